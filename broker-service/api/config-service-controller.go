@@ -6,9 +6,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
 )
 
 func CreateServer(c *gin.Context) {
@@ -139,6 +142,652 @@ func DbOverviewByName(c *gin.Context) {
 	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
 }
 
+func DeleteDatabase(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name")
+	if c.Request.URL.RawQuery != "" {
+		url += "?" + c.Request.URL.RawQuery
+	}
+
+	request, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func UpdateDatabase(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name")
+	request, err := http.NewRequest("PATCH", url, c.Request.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func StopDatabase(c *gin.Context) {
+	postDatabaseAction(c, "stop")
+}
+
+func StartDatabase(c *gin.Context) {
+	postDatabaseAction(c, "start")
+}
+
+func RestartDatabase(c *gin.Context) {
+	postDatabaseAction(c, "restart")
+}
+
+func postDatabaseAction(c *gin.Context, action string) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/" + action
+	request, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func ResizeDatabase(c *gin.Context) {
+	var requestPayload dto.ResizeDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/resize"
+	request, err := http.NewRequest("POST", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func StorageUsage(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/storage"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func GrowStorage(c *gin.Context) {
+	var requestPayload dto.StorageDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/storage"
+	request, err := http.NewRequest("PATCH", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func ChangePassword(c *gin.Context) {
+	var requestPayload dto.PasswordDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/password"
+	request, err := http.NewRequest("PUT", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func CloneDatabase(c *gin.Context) {
+	var requestPayload dto.CloneDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/clone"
+	request, err := http.NewRequest("POST", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func RestoreDatabase(c *gin.Context) {
+	var requestPayload dto.RestoreDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/restore"
+	request, err := http.NewRequest("POST", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func UpgradeDatabase(c *gin.Context) {
+	var requestPayload dto.UpgradeDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/upgrade"
+	request, err := http.NewRequest("POST", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func ConfirmUpgrade(c *gin.Context) {
+	postDatabaseAction(c, "upgrade/confirm")
+}
+
+func RollbackUpgrade(c *gin.Context) {
+	postDatabaseAction(c, "upgrade/rollback")
+}
+
+func MigrateDatabase(c *gin.Context) {
+	var requestPayload dto.MigrateDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/migrate"
+	request, err := http.NewRequest("POST", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func CreateReplicas(c *gin.Context) {
+	var requestPayload dto.ReplicasDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/replicas"
+	request, err := http.NewRequest("POST", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func DeleteReplica(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/replicas/" + c.Param("replica")
+	request, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func ComputeTiers(c *gin.Context) {
+
+	url := utils.URL.ConfigServiceUrl + "/tiers"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func ReconcileReport(c *gin.Context) {
+
+	url := utils.URL.ConfigServiceUrl + "/reconciliation"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
 func DbGrafanaUIDByName(c *gin.Context) {
 	tokenString := c.GetHeader("Authorization")
 	if tokenString == "" {
@@ -148,7 +797,285 @@ func DbGrafanaUIDByName(c *gin.Context) {
 
 	userEmail := utils.GetEmailFromJwt(tokenString)
 
-	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/grafana"
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/grafana"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func DbConnectionByName(c *gin.Context) {
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/connection"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func UserDatabases(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func UserServers(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/servers"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func UserVolumes(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/volumes"
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func PurgeVolume(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/volumes/" + c.Param("uuid")
+	request, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func DeploymentStatus(c *gin.Context) {
+	deploymentUUID := c.Param("uuid")
+
+	result, err := utils.RedisClient.Get(deploymentUUID).Bytes()
+	if err == redis.Nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	// Deployments tracked by older nodes still hold a plain status string.
+	var status dto.DeploymentStatusDto
+	if err := json.Unmarshal(result, &status); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"status": string(result),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": status,
+	})
+}
+
+func CancelDeployment(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/deployments/" + c.Param("uuid")
+	request, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func CreateBackup(c *gin.Context) {
+	postDatabaseAction(c, "backups")
+}
+
+func DatabaseBackups(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/backups"
+	if c.Request.URL.RawQuery != "" {
+		url += "?" + c.Request.URL.RawQuery
+	}
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -173,7 +1100,7 @@ func DbGrafanaUIDByName(c *gin.Context) {
 	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
 }
 
-func UserDatabases(c *gin.Context) {
+func DownloadBackup(c *gin.Context) {
 
 	tokenString := c.GetHeader("Authorization")
 	if tokenString == "" {
@@ -183,7 +1110,7 @@ func UserDatabases(c *gin.Context) {
 
 	userEmail := utils.GetEmailFromJwt(tokenString)
 
-	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases"
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/backups/" + c.Param("backup") + "/download"
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -198,6 +1125,117 @@ func UserDatabases(c *gin.Context) {
 	}
 	defer response.Body.Close()
 
+	headers := map[string]string{}
+	for _, header := range []string{"Content-Disposition", "X-Checksum-Sha256"} {
+		if value := response.Header.Get(header); value != "" {
+			headers[header] = value
+		}
+	}
+
+	c.DataFromReader(response.StatusCode, response.ContentLength, response.Header.Get("Content-Type"), response.Body, headers)
+}
+
+func BackupPolicy(c *gin.Context) {
+	forwardBackupPolicy(c, "GET", nil)
+}
+
+func UpdateBackupPolicy(c *gin.Context) {
+	var requestPayload dto.BackupPolicyDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	forwardBackupPolicy(c, "PUT", &buffer)
+}
+
+func ResetBackupPolicy(c *gin.Context) {
+	forwardBackupPolicy(c, "DELETE", nil)
+}
+
+func forwardBackupPolicy(c *gin.Context, method string, body io.Reader) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/backup-policy"
+	request, err := http.NewRequest(method, url, body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), responseBody)
+}
+
+func RestoreBackup(c *gin.Context) {
+	var requestPayload dto.RestoreBackupDto
+
+	if err := c.BindJSON(&requestPayload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+
+	if err := encoder.Encode(requestPayload); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/backups/" + c.Param("backup") + "/restore"
+	request, err := http.NewRequest("POST", url, &buffer)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -208,7 +1246,9 @@ func UserDatabases(c *gin.Context) {
 	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
 }
 
-func UserServers(c *gin.Context) {
+// ImportDatabase streams the uploaded dump straight through to the config
+// service instead of buffering it like template uploads, dumps can be large.
+func ImportDatabase(c *gin.Context) {
 
 	tokenString := c.GetHeader("Authorization")
 	if tokenString == "" {
@@ -218,7 +1258,77 @@ func UserServers(c *gin.Context) {
 
 	userEmail := utils.GetEmailFromJwt(tokenString)
 
-	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/servers"
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a multipart upload"})
+		return
+	}
+
+	var file *multipart.Part
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Can't read file from request"})
+			return
+		}
+		if part.FormName() == "file" {
+			file = part
+			break
+		}
+	}
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/import"
+	request, err := http.NewRequest("POST", url, file)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("X-Filename", file.FileName())
+
+	// The request length also counts the multipart framing, only a size given
+	// for the file itself is passed on.
+	uploadSize := file.Header.Get("Content-Length")
+	if uploadSize == "" {
+		uploadSize = c.GetHeader("X-Upload-Size")
+	}
+	if size, err := strconv.ParseInt(uploadSize, 10, 64); err == nil && size > 0 {
+		request.Header.Set("X-Upload-Size", strconv.FormatInt(size, 10))
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+}
+
+func DatabaseImports(c *gin.Context) {
+
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/imports"
+	if c.Param("import") != "" {
+		url += "/" + c.Param("import")
+	}
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -243,12 +1353,59 @@ func UserServers(c *gin.Context) {
 	c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
 }
 
-func DeploymentStatus(c *gin.Context) {
-	deploymentUUID := c.Param("uuid")
+// ExportDatabase relays a live dump chunk by chunk. Failures after streaming
+// started arrive in the X-Export-Error trailer and are passed on as such.
+func ExportDatabase(c *gin.Context) {
 
-	deploymentStatus := utils.RedisClient.Get(deploymentUUID)
-	result, _ := deploymentStatus.Result()
-	c.JSON(http.StatusOK, gin.H{
-		"status": result,
-	})
+	tokenString := c.GetHeader("Authorization")
+	if tokenString == "" {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	userEmail := utils.GetEmailFromJwt(tokenString)
+
+	url := utils.URL.ConfigServiceUrl + "/users/" + userEmail + "/databases/" + c.Param("name") + "/export"
+	if c.Request.URL.RawQuery != "" {
+		url += "?" + c.Request.URL.RawQuery
+	}
+	request, err := http.NewRequestWithContext(c.Request.Context(), "GET", url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.Data(response.StatusCode, response.Header.Get("Content-Type"), body)
+		return
+	}
+
+	c.Header("Content-Type", response.Header.Get("Content-Type"))
+	c.Header("Content-Disposition", response.Header.Get("Content-Disposition"))
+	c.Header("Trailer", "X-Export-Error")
+	c.Status(http.StatusOK)
+
+	_, err = io.Copy(c.Writer, response.Body)
+	if err != nil {
+		c.Header("X-Export-Error", "Export was interrupted")
+		return
+	}
+
+	if exportError := response.Trailer.Get("X-Export-Error"); exportError != "" {
+		c.Header("X-Export-Error", exportError)
+	}
 }
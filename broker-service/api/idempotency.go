@@ -0,0 +1,138 @@
+package api
+
+import (
+	"broker-service/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+)
+
+const (
+	idempotencyKeyHeader  = "Idempotency-Key"
+	idempotencyKeyTTL     = 24 * time.Hour
+	idempotencyPendingTTL = 5 * time.Minute
+	idempotencyPending    = "PENDING"
+)
+
+type idempotentResponse struct {
+	State       string `json:"state"`
+	RequestHash string `json:"request_hash"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func Idempotent(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+
+	requestBody, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(requestBody))
+
+	hash := sha256.Sum256(requestBody)
+	requestHash := hex.EncodeToString(hash[:])
+
+	redisKey := "idempotency:" + utils.GetEmailFromJwt(c.GetHeader("Authorization")) + ":" + c.Request.Method + ":" + c.FullPath() + ":" + key
+
+	pending, _ := json.Marshal(idempotentResponse{State: idempotencyPending, RequestHash: requestHash})
+
+	acquired, err := utils.RedisClient.SetNX(redisKey, pending, idempotencyPendingTTL).Result()
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !acquired {
+		replayResponse(c, redisKey, requestHash)
+		return
+	}
+
+	// A panicking handler must not leave the key pending, retries would get
+	// a conflict until it expires.
+	defer func() {
+		if err := recover(); err != nil {
+			utils.RedisClient.Del(redisKey)
+			panic(err)
+		}
+	}()
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+
+	c.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		utils.RedisClient.Del(redisKey)
+		return
+	}
+
+	stored, _ := json.Marshal(idempotentResponse{
+		RequestHash: requestHash,
+		StatusCode:  recorder.Status(),
+		ContentType: recorder.Header().Get("Content-Type"),
+		Body:        recorder.body.Bytes(),
+	})
+
+	utils.RedisClient.Set(redisKey, stored, idempotencyKeyTTL)
+}
+
+func replayResponse(c *gin.Context, redisKey string, requestHash string) {
+
+	data, err := utils.RedisClient.Get(redisKey).Bytes()
+	if err == redis.Nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Idempotency key expired, try again"})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var stored idempotentResponse
+	if err := json.Unmarshal(data, &stored); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if stored.RequestHash != requestHash {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency key was already used with a different request body"})
+		c.Abort()
+		return
+	}
+
+	if stored.State == idempotencyPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Request with this idempotency key is still in progress"})
+		c.Abort()
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+	c.Abort()
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type ServerDto struct {
 	Name     string `json:"name"`
 	Location string `json:"location"`
@@ -29,3 +31,70 @@ type ConfigurationDto struct {
 	MaxStorageSize  string `json:"max_storage_size"`
 	StorageSizeUnit string `json:"storage_size_unit"`
 }
+
+type ResizeDto struct {
+	ServiceType string `json:"service_type"`
+	ComputeType string `json:"compute_type"`
+}
+
+type StorageDto struct {
+	MaxStorageSize  string `json:"max_storage_size"`
+	StorageSizeUnit string `json:"storage_size_unit"`
+}
+
+type PasswordDto struct {
+	Password string `json:"password"`
+}
+
+type UpgradeDto struct {
+	Version string `json:"version"`
+}
+
+type MigrateDto struct {
+	Server string `json:"server"`
+}
+
+type ReplicasDto struct {
+	Count int `json:"count"`
+}
+
+type BackupPolicyDto struct {
+	Schedule   string `json:"schedule"`
+	KeepDaily  int    `json:"keep_daily"`
+	KeepWeekly int    `json:"keep_weekly"`
+}
+
+type CloneDto struct {
+	Name        string `json:"name"`
+	Password    string `json:"password"`
+	Server      string `json:"server"`
+	Environment string `json:"environment"`
+}
+
+type RestoreBackupDto struct {
+	Name        string `json:"name"`
+	Password    string `json:"password"`
+	Environment string `json:"environment"`
+}
+
+type RestoreDto struct {
+	Name        string    `json:"name"`
+	Password    string    `json:"password"`
+	Environment string    `json:"environment"`
+	TargetTime  time.Time `json:"target_time"`
+}
+
+type DeploymentStatusDto struct {
+	State   string              `json:"state"`
+	Step    string              `json:"step"`
+	Message string              `json:"message"`
+	Percent int                 `json:"percent"`
+	Error   *DeploymentErrorDto `json:"error,omitempty"`
+}
+
+type DeploymentErrorDto struct {
+	Step     string `json:"step"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+	Output   string `json:"output,omitempty"`
+}
@@ -17,8 +17,8 @@ func main() {
 
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Idempotency-Key")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusOK)
 			return
@@ -29,13 +29,45 @@ func main() {
 	router.POST("/signup", api.Signup)
 	router.POST("/login", api.Login)
 
-	router.POST("/servers", api.AuthenticateUser, api.CreateServer)
-	router.POST("/databases", api.AuthenticateUser, api.CreateDatabase)
+	router.POST("/servers", api.AuthenticateUser, api.Idempotent, api.CreateServer)
+	router.POST("/databases", api.AuthenticateUser, api.Idempotent, api.CreateDatabase)
 	router.GET("/users/databases/:name", api.AuthenticateUser, api.DbOverviewByName)
+	router.DELETE("/users/databases/:name", api.AuthenticateUser, api.DeleteDatabase)
+	router.PATCH("/users/databases/:name", api.AuthenticateUser, api.UpdateDatabase)
+	router.POST("/users/databases/:name/stop", api.AuthenticateUser, api.StopDatabase)
+	router.POST("/users/databases/:name/start", api.AuthenticateUser, api.StartDatabase)
+	router.POST("/users/databases/:name/restart", api.AuthenticateUser, api.RestartDatabase)
+	router.POST("/users/databases/:name/resize", api.AuthenticateUser, api.ResizeDatabase)
+	router.GET("/users/databases/:name/storage", api.AuthenticateUser, api.StorageUsage)
+	router.PATCH("/users/databases/:name/storage", api.AuthenticateUser, api.GrowStorage)
+	router.PUT("/users/databases/:name/password", api.AuthenticateUser, api.ChangePassword)
+	router.POST("/users/databases/:name/clone", api.AuthenticateUser, api.Idempotent, api.CloneDatabase)
+	router.POST("/users/databases/:name/restore", api.AuthenticateUser, api.Idempotent, api.RestoreDatabase)
+	router.POST("/users/databases/:name/upgrade", api.AuthenticateUser, api.UpgradeDatabase)
+	router.POST("/users/databases/:name/upgrade/confirm", api.AuthenticateUser, api.ConfirmUpgrade)
+	router.POST("/users/databases/:name/upgrade/rollback", api.AuthenticateUser, api.RollbackUpgrade)
+	router.POST("/users/databases/:name/migrate", api.AuthenticateUser, api.Idempotent, api.MigrateDatabase)
+	router.POST("/users/databases/:name/replicas", api.AuthenticateUser, api.Idempotent, api.CreateReplicas)
+	router.DELETE("/users/databases/:name/replicas/:replica", api.AuthenticateUser, api.DeleteReplica)
 	router.GET("/users/databases", api.AuthenticateUser, api.UserDatabases)
 	router.GET("/users/servers", api.AuthenticateUser, api.UserServers)
+	router.GET("/users/volumes", api.AuthenticateUser, api.UserVolumes)
+	router.DELETE("/users/volumes/:uuid", api.AuthenticateUser, api.PurgeVolume)
 	router.GET("/users/databases/:name/grafana", api.AuthenticateUser, api.DbGrafanaUIDByName)
+	router.GET("/users/databases/:name/connection", api.AuthenticateUser, api.DbConnectionByName)
+	router.POST("/users/databases/:name/backups", api.AuthenticateUser, api.CreateBackup)
+	router.GET("/users/databases/:name/backups", api.AuthenticateUser, api.DatabaseBackups)
+	router.GET("/users/databases/:name/backups/:backup/download", api.AuthenticateUser, api.DownloadBackup)
+	router.POST("/users/backups/:backup/restore", api.AuthenticateUser, api.Idempotent, api.RestoreBackup)
+	router.POST("/users/databases/:name/import", api.AuthenticateUser, api.ImportDatabase)
+	router.GET("/users/databases/:name/imports", api.AuthenticateUser, api.DatabaseImports)
+	router.GET("/users/databases/:name/imports/:import", api.AuthenticateUser, api.DatabaseImports)
+	router.GET("/users/databases/:name/export", api.AuthenticateUser, api.ExportDatabase)
+	router.GET("/users/databases/:name/backup-policy", api.AuthenticateUser, api.BackupPolicy)
+	router.PUT("/users/databases/:name/backup-policy", api.AuthenticateUser, api.UpdateBackupPolicy)
+	router.DELETE("/users/databases/:name/backup-policy", api.AuthenticateUser, api.ResetBackupPolicy)
 	router.GET("/deployments/:uuid", api.DeploymentStatus)
+	router.DELETE("/deployments/:uuid", api.AuthenticateUser, api.CancelDeployment)
 
 	router.POST("/regions/:region/types/:type/versions/:version", api.AuthenticateAdmin, api.UploadFile)
 	router.POST("/regions", api.AuthenticateAdmin, api.NewRegion)
@@ -43,8 +75,10 @@ func main() {
 	router.POST("/regions/:region/types/:type/versions", api.AuthenticateAdmin, api.NewVersion)
 
 	router.GET("/regions", api.Authenticate, api.GetAll)
+	router.GET("/tiers", api.Authenticate, api.ComputeTiers)
 
 	router.POST("/subscriptions", api.AuthenticateAdmin, api.Subscribe)
+	router.GET("/admin/reconciliation", api.AuthenticateAdmin, api.ReconcileReport)
 
 	router.Run()
 }
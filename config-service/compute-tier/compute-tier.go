@@ -0,0 +1,32 @@
+package compute
+
+type Tier struct {
+	CPU            float64 `json:"cpu"`
+	MemoryMB       int64   `json:"memory_mb"`
+	MaxConnections int     `json:"max_connections"`
+}
+
+var TierMp = map[string]map[string]Tier{
+	"Burstable": {
+		"B1ms": {CPU: 1, MemoryMB: 2048, MaxConnections: 50},
+		"B2s":  {CPU: 2, MemoryMB: 4096, MaxConnections: 100},
+	},
+	"General Purpose": {
+		"D2s": {CPU: 2, MemoryMB: 8192, MaxConnections: 200},
+		"D4s": {CPU: 4, MemoryMB: 16384, MaxConnections: 400},
+	},
+	"Memory Optimized": {
+		"E2s": {CPU: 2, MemoryMB: 16384, MaxConnections: 300},
+		"E4s": {CPU: 4, MemoryMB: 32768, MaxConnections: 600},
+	},
+}
+
+func GetTier(serviceType string, computeType string) (Tier, bool) {
+	tiers, exists := TierMp[serviceType]
+	if !exists {
+		return Tier{}, false
+	}
+
+	tier, exists := tiers[computeType]
+	return tier, exists
+}
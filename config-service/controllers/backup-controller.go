@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"config-service/dto"
+	"config-service/keyring"
+	"config-service/models"
+	"config-service/node-info"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+	"net/rpc"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	backupKeyHeader      = "X-Backup-Key"
+	backupChecksumHeader = "X-Backup-Checksum"
+)
+
+type CreateBackupPayload struct {
+	UUID       uuid.UUID
+	BackupUUID uuid.UUID
+	Key        []byte
+}
+
+type CreateBackupResponse struct {
+	Status     string
+	SizeBytes  int64
+	Checksum   string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      string
+}
+
+type DeleteBackupPayload struct {
+	UUID       uuid.UUID
+	BackupUUID uuid.UUID
+}
+
+type DeleteBackupResponse struct {
+	Status string
+}
+
+func CreateBackup(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	backup := newBackupEntry(database, models.BackupKindManual)
+
+	err = models.DB.BackupEntry.Insert(backup)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	go createBackup(backup, server)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"uuid": backup.BackupUUID,
+	})
+}
+
+func newBackupEntry(database *models.DatabaseEntry, kind string) models.BackupEntry {
+	return models.BackupEntry{
+		BackupUUID:    uuid.New().String(),
+		DatabaseID:    database.ID.Hex(),
+		DirectoryUUID: database.DirectoryUUID,
+		Database:      database.Name,
+		Server:        database.Server,
+		Email:         database.Email,
+		Type:          database.Type,
+		Version:       database.Version,
+		Environment:   database.Environment,
+		Connectivity:  database.Connectivity,
+		Configuration: database.Configuration,
+		Kind:          kind,
+		Status:        models.BackupCreating,
+		CreatedAt:     time.Now(),
+		Encrypted:     true,
+	}
+}
+
+// backupKey returns the tenant key a backup was encrypted with, or nil for
+// backups taken before encryption.
+func backupKey(backup *models.BackupEntry) ([]byte, error) {
+	if !backup.Encrypted {
+		return nil, nil
+	}
+	return keyring.TenantKey(backup.Email)
+}
+
+func createBackup(backup models.BackupEntry, server *models.ServerEntry) bool {
+
+	directoryUUID, err := uuid.Parse(backup.DirectoryUUID)
+	if err != nil {
+		models.DB.BackupEntry.Fail(backup.BackupUUID, "Invalid deployment uuid")
+		return false
+	}
+
+	backupUUID, err := uuid.Parse(backup.BackupUUID)
+	if err != nil {
+		models.DB.BackupEntry.Fail(backup.BackupUUID, "Invalid backup uuid")
+		return false
+	}
+
+	key, err := backupKey(&backup)
+	if err != nil {
+		log.Println("Error getting backup key:", err)
+		models.DB.BackupEntry.Fail(backup.BackupUUID, "Backup key unavailable")
+		return false
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.BackupEntry.Fail(backup.BackupUUID, "Can't reach node")
+		return false
+	}
+	defer client.Close()
+
+	var reply CreateBackupResponse
+	payload := CreateBackupPayload{
+		UUID:       directoryUUID,
+		BackupUUID: backupUUID,
+		Key:        key,
+	}
+
+	err = client.Call("RPCServer.CreateBackup", payload, &reply)
+	if err != nil {
+		log.Println("Error when calling node rpc")
+		models.DB.BackupEntry.Fail(backup.BackupUUID, err.Error())
+		return false
+	}
+
+	if reply.Status != "CREATED" {
+		log.Println("Error: Node failed to create backup")
+		models.DB.BackupEntry.Fail(backup.BackupUUID, reply.Error)
+		return false
+	}
+
+	err = models.DB.BackupEntry.Complete(backup.BackupUUID, reply.SizeBytes, reply.Checksum, reply.StartedAt, reply.FinishedAt)
+	if err != nil {
+		log.Println("Error: Failed to record completed backup")
+		return false
+	}
+
+	return true
+}
+
+func deleteBackup(backup *models.BackupEntry, server *models.ServerEntry) error {
+
+	directoryUUID, err := uuid.Parse(backup.DirectoryUUID)
+	if err != nil {
+		return err
+	}
+
+	backupUUID, err := uuid.Parse(backup.BackupUUID)
+	if err != nil {
+		return err
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var reply DeleteBackupResponse
+	payload := DeleteBackupPayload{
+		UUID:       directoryUUID,
+		BackupUUID: backupUUID,
+	}
+
+	err = client.Call("RPCServer.DeleteBackup", payload, &reply)
+	if err != nil {
+		return err
+	}
+
+	if reply.Status != "DELETED" {
+		return errors.New("node failed to delete backup " + backup.BackupUUID)
+	}
+
+	return models.DB.BackupEntry.Delete(backup.BackupUUID)
+}
+
+func DatabaseBackups(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	backups, err := models.DB.BackupEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	kind := c.Query("kind")
+	status := c.Query("status")
+
+	response := []dto.BackupDto{}
+	for _, backup := range backups {
+		if kind != "" && backup.Kind != kind {
+			continue
+		}
+		if status != "" && backup.Status != status {
+			continue
+		}
+
+		response = append(response, dto.BackupDto{
+			UUID:       backup.BackupUUID,
+			Database:   backup.Database,
+			Kind:       backup.Kind,
+			Status:     backup.Status,
+			SizeBytes:  backup.SizeBytes,
+			Checksum:   backup.Checksum,
+			CreatedAt:  backup.CreatedAt,
+			StartedAt:  backup.StartedAt,
+			FinishedAt: backup.FinishedAt,
+			Error:      backup.Error,
+			Encrypted:  backup.Encrypted,
+
+			LastVerifiedAt:    backup.LastVerifiedAt,
+			VerificationError: backup.VerificationError,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+	})
+}
+
+func DownloadBackup(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	backup, err := models.DB.BackupEntry.GetOne(c.Param("backup"), email)
+	if err != nil || backup.DatabaseID != database.ID.Hex() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if backup.Status != models.BackupCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Backup is " + backup.Status})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(backup.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	key, err := backupKey(backup)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	url := "http://" + node.LocationServerMp[server.Location] + "/backups/" + backup.DirectoryUUID + "/" + backup.BackupUUID
+	request, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if key != nil {
+		request.Header.Set(backupKeyHeader, base64.StdEncoding.EncodeToString(key))
+	}
+	request.Header.Set(backupChecksumHeader, backup.Checksum)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Backup file is not available on the node"})
+		return
+	}
+
+	headers := map[string]string{
+		"Content-Disposition": "attachment; filename=\"" + database.Name + "-" + backup.BackupUUID + ".dump\"",
+	}
+	// The checksum covers the stored archive, which for encrypted backups is
+	// not what is being downloaded.
+	if !backup.Encrypted {
+		headers["X-Checksum-Sha256"] = backup.Checksum
+	}
+
+	c.DataFromReader(http.StatusOK, response.ContentLength, "application/octet-stream", response.Body, headers)
+}
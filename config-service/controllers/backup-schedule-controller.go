@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"config-service/dto"
+	"config-service/models"
+	"config-service/schedule"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	backupSchedulerInterval = time.Minute
+	backupFailureRetention  = 30 * 24 * time.Hour
+)
+
+var productionBackupPolicy = models.BackupPolicy{
+	Schedule:   "0 2 * * *",
+	KeepDaily:  7,
+	KeepWeekly: 4,
+}
+
+var developmentBackupPolicy = models.BackupPolicy{
+	Schedule:   "0 3 * * 0",
+	KeepDaily:  0,
+	KeepWeekly: 4,
+}
+
+func defaultBackupPolicy(environment string) models.BackupPolicy {
+	switch strings.ToLower(environment) {
+	case "production", "prod":
+		return productionBackupPolicy
+	default:
+		return developmentBackupPolicy
+	}
+}
+
+func effectiveBackupPolicy(database *models.DatabaseEntry) models.BackupPolicy {
+	if database.BackupPolicy != nil {
+		return *database.BackupPolicy
+	}
+	return defaultBackupPolicy(database.Environment)
+}
+
+func ScheduleBackups() {
+	for {
+		time.Sleep(backupSchedulerInterval)
+		runScheduledBackups()
+	}
+}
+
+func runScheduledBackups() {
+
+	databases, err := models.DB.DatabaseEntry.GetAllEntries()
+	if err != nil {
+		log.Println("Error getting databases for scheduled backups:", err)
+		return
+	}
+
+	now := time.Now()
+
+	for _, database := range databases {
+		if !reconcilableStatuses[database.Status] {
+			continue
+		}
+
+		policy := effectiveBackupPolicy(database)
+
+		backupSchedule, err := schedule.Parse(policy.Schedule)
+		if err != nil {
+			log.Println("Invalid backup schedule for database", database.DirectoryUUID, err)
+			continue
+		}
+
+		if database.NextBackupAt.IsZero() {
+			models.DB.DatabaseEntry.UpdateNextBackupAt(database.DirectoryUUID, backupSchedule.Next(now))
+			continue
+		}
+
+		if now.Before(database.NextBackupAt) {
+			continue
+		}
+
+		// Advance the schedule before starting so a slow or failing run
+		// isn't triggered again on the next tick.
+		err = models.DB.DatabaseEntry.UpdateNextBackupAt(database.DirectoryUUID, backupSchedule.Next(now))
+		if err != nil {
+			continue
+		}
+
+		backup := newBackupEntry(database, models.BackupKindScheduled)
+
+		if database.Status != models.StatusOnline {
+			backup.Status = models.BackupFailed
+			backup.Error = "Database is " + database.Status
+			backup.FinishedAt = now
+			models.DB.BackupEntry.Insert(backup)
+			continue
+		}
+
+		server, err := models.DB.ServerEntry.GetOne(database.Server)
+		if err != nil {
+			continue
+		}
+
+		err = models.DB.BackupEntry.Insert(backup)
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			if createBackup(backup, server) {
+				pruneBackups(database, server, policy)
+			}
+		}()
+	}
+}
+
+func pruneBackups(database *models.DatabaseEntry, server *models.ServerEntry, policy models.BackupPolicy) {
+
+	backups, err := models.DB.BackupEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		log.Println("Error getting backups to prune:", err)
+		return
+	}
+
+	var completed []*models.BackupEntry
+	for _, backup := range backups {
+		if backup.Kind != models.BackupKindScheduled {
+			continue
+		}
+
+		switch backup.Status {
+		case models.BackupCompleted:
+			completed = append(completed, backup)
+		case models.BackupFailed:
+			if time.Since(backup.CreatedAt) > backupFailureRetention {
+				models.DB.BackupEntry.Delete(backup.BackupUUID)
+			}
+		}
+	}
+
+	retained := retainedBackups(completed, policy)
+
+	for _, backup := range completed {
+		if retained[backup.BackupUUID] {
+			continue
+		}
+
+		err = deleteBackup(backup, server)
+		if err != nil {
+			log.Println("Error pruning backup", backup.BackupUUID, err)
+		}
+	}
+}
+
+// retainedBackups keeps the newest backup of each of the last KeepDaily days
+// and of each of the last KeepWeekly weeks that have a backup.
+func retainedBackups(backups []*models.BackupEntry, policy models.BackupPolicy) map[string]bool {
+
+	backups = slices.Clone(backups)
+	slices.SortFunc(backups, func(a, b *models.BackupEntry) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	retained := map[string]bool{}
+	days := map[string]bool{}
+	weeks := map[string]bool{}
+
+	for _, backup := range backups {
+		createdAt := backup.CreatedAt.UTC()
+
+		day := createdAt.Format(time.DateOnly)
+		if !days[day] && len(days) < policy.KeepDaily {
+			days[day] = true
+			retained[backup.BackupUUID] = true
+		}
+
+		year, number := createdAt.ISOWeek()
+		week := strconv.Itoa(year) + "-" + strconv.Itoa(number)
+		if !weeks[week] && len(weeks) < policy.KeepWeekly {
+			weeks[week] = true
+			retained[backup.BackupUUID] = true
+		}
+	}
+
+	return retained
+}
+
+func BackupPolicy(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	policy := effectiveBackupPolicy(database)
+
+	nextRunAt := database.NextBackupAt
+	if nextRunAt.IsZero() {
+		backupSchedule, err := schedule.Parse(policy.Schedule)
+		if err == nil {
+			nextRunAt = backupSchedule.Next(time.Now())
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": dto.BackupPolicyOverviewDto{
+			Schedule:   policy.Schedule,
+			KeepDaily:  policy.KeepDaily,
+			KeepWeekly: policy.KeepWeekly,
+			IsDefault:  database.BackupPolicy == nil,
+			NextRunAt:  nextRunAt,
+		},
+	})
+}
+
+func UpdateBackupPolicy(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var policyDto dto.BackupPolicyDto
+
+	if err := c.BindJSON(&policyDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	if _, err := schedule.Parse(policyDto.Schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if policyDto.KeepDaily < 0 || policyDto.KeepWeekly < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Retention counts can't be negative"})
+		return
+	}
+
+	if policyDto.KeepDaily == 0 && policyDto.KeepWeekly == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Retention policy must keep at least one backup"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	err = models.DB.DatabaseEntry.UpdateBackupPolicy(database.DirectoryUUID, &models.BackupPolicy{
+		Schedule:   policyDto.Schedule,
+		KeepDaily:  policyDto.KeepDaily,
+		KeepWeekly: policyDto.KeepWeekly,
+	})
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func ResetBackupPolicy(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	err = models.DB.DatabaseEntry.UpdateBackupPolicy(database.DirectoryUUID, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
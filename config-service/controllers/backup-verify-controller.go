@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"config-service/models"
+	"config-service/node-info"
+	"errors"
+	"log"
+	"net/rpc"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	backupVerifierInterval   = time.Hour
+	backupVerificationPeriod = 7 * 24 * time.Hour
+	backupVerificationSample = 3
+)
+
+type VerifyBackupPayload struct {
+	SourceUUID uuid.UUID
+	BackupUUID uuid.UUID
+	Type       string
+	Version    string
+	User       string
+	Key        []byte
+	Checksum   string
+	StorageMB  int64
+}
+
+type VerifyBackupResponse struct {
+	Status string
+	Tables int
+	Rows   int64
+	Error  string
+}
+
+func VerifyBackups() {
+	for {
+		time.Sleep(backupVerifierInterval)
+		runBackupVerification()
+	}
+}
+
+// runBackupVerification restores a few of the least recently checked backups
+// on their nodes, so every backup gets exercised roughly once a period.
+func runBackupVerification() {
+
+	backups, err := models.DB.BackupEntry.GetVerificationCandidates(time.Now().Add(-backupVerificationPeriod), backupVerificationSample)
+	if err != nil {
+		log.Println("Error getting backups to verify:", err)
+		return
+	}
+
+	for _, backup := range backups {
+		checkedAt := time.Now()
+
+		reason := ""
+		err := verifyBackup(backup)
+		if err != nil {
+			log.Println("Backup", backup.BackupUUID, "failed verification:", err)
+			reason = err.Error()
+		}
+
+		models.DB.BackupEntry.RecordVerification(backup.BackupUUID, checkedAt, reason)
+	}
+}
+
+func verifyBackup(backup *models.BackupEntry) error {
+
+	if backup.Type == "" {
+		return errors.New("backup was taken before restores were supported")
+	}
+
+	sourceUUID, err := uuid.Parse(backup.DirectoryUUID)
+	if err != nil {
+		return err
+	}
+
+	backupUUID, err := uuid.Parse(backup.BackupUUID)
+	if err != nil {
+		return err
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(backup.Server)
+	if err != nil {
+		return err
+	}
+
+	key, err := backupKey(backup)
+	if err != nil {
+		return errors.New("backup key unavailable")
+	}
+
+	storageMB, _ := storageSizeMB(backup.Configuration.MaxStorageSize, backup.Configuration.StorageSizeUnit)
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		return errors.New("can't reach node for server " + server.Name)
+	}
+	defer client.Close()
+
+	var reply VerifyBackupResponse
+	payload := VerifyBackupPayload{
+		SourceUUID: sourceUUID,
+		BackupUUID: backupUUID,
+		Type:       backup.Type,
+		Version:    backup.Version,
+		User:       server.Admin,
+		Key:        key,
+		Checksum:   backup.Checksum,
+		StorageMB:  storageMB,
+	}
+
+	err = client.Call("RPCServer.VerifyBackup", payload, &reply)
+	if err != nil {
+		return err
+	}
+
+	if reply.Status != "VERIFIED" {
+		return errors.New(reply.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"config-service/compute-tier"
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type CloneDatabasePayload struct {
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	User         string
+	UUID         uuid.UUID
+	Limits       ComputeLimits
+	StorageMB    int64
+	SourceURL    string
+	Connectivity string
+}
+
+func CloneDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var cloneDto dto.CloneDto
+
+	if err := c.BindJSON(&cloneDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	if !databaseNameRegex.MatchString(cloneDto.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid database name"})
+		return
+	}
+
+	source, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if source.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + source.Status,
+		})
+		return
+	}
+
+	if _, err := models.DB.DatabaseEntry.GetOne(cloneDto.Name, email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database " + cloneDto.Name + " already exists"})
+		return
+	}
+
+	if cloneDto.Server == "" {
+		cloneDto.Server = source.Server
+	}
+
+	if cloneDto.Environment == "" {
+		cloneDto.Environment = source.Environment
+	}
+
+	generated := cloneDto.Password == ""
+	if generated {
+		cloneDto.Password, err = generatePassword()
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	sourceServer, err := models.DB.ServerEntry.GetOne(source.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	targetServer, err := models.DB.ServerEntry.GetOne(cloneDto.Server)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown server " + cloneDto.Server})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(cloneDto.Password), 10)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID := uuid.New()
+
+	database := models.DatabaseEntry{
+		Name:          cloneDto.Name,
+		Password:      string(hash),
+		Server:        cloneDto.Server,
+		Environment:   cloneDto.Environment,
+		Configuration: source.Configuration,
+		Connectivity:  source.Connectivity,
+		Type:          source.Type,
+		Version:       source.Version,
+		DirectoryUUID: directoryUUID.String(),
+		GrafanaUID:    "",
+		Email:         email,
+		CreatedAt:     time.Now(),
+		Status:        models.StatusProvisioning,
+		PasswordSetAt: time.Now(),
+		Provisioning: &models.Provisioning{
+			State: models.StatusProvisioning,
+			Steps: []models.ProvisioningStep{
+				{Name: models.StepAccepted, At: time.Now()},
+			},
+		},
+	}
+
+	err = models.DB.DatabaseEntry.Insert(database)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	tier, _ := compute.GetTier(source.Configuration.ServiceType, source.Configuration.ComputeType)
+	storageMB, _ := storageSizeMB(source.Configuration.MaxStorageSize, source.Configuration.StorageSizeUnit)
+
+	payload := CloneDatabasePayload{
+		Name:         cloneDto.Name,
+		Type:         source.Type,
+		Version:      source.Version,
+		User:         targetServer.Admin,
+		Password:     cloneDto.Password,
+		UUID:         directoryUUID,
+		Limits:       ComputeLimits(tier),
+		StorageMB:    storageMB,
+		SourceURL:    "http://" + node.LocationServerMp[sourceServer.Location] + "/exports/" + source.DirectoryUUID + "?format=custom",
+		Connectivity: source.Connectivity,
+	}
+
+	go requestProvisioning(targetServer, directoryUUID.String(), "RPCServer.CloneDatabase", payload)
+
+	response := gin.H{
+		"uuid": directoryUUID.String(),
+	}
+	if generated {
+		response["password"] = cloneDto.Password
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"config-service/dto"
+	"config-service/models"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	connectionSSLMode    = "disable"
+	passwordPlaceholder  = "{your_password}"
+	connectionDriverName = "postgres"
+)
+
+func DbConnectionByName(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Type != "PostgreSQL" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Connection strings are only available for PostgreSQL databases"})
+		return
+	}
+
+	if database.Connectivity == connectivityPrivate {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database is " + connectivityPrivate + " and only accepts connections on its node, make it " + connectivityPublic + " to connect to it"})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	host := database.NodeIP
+	port := database.NodePort
+	user := server.Admin
+	dbName := database.Name
+
+	keyValue := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
+		host, port, quoteKeyValue(dbName), quoteKeyValue(user), passwordPlaceholder, connectionSSLMode)
+
+	response := dto.ConnectionDto{
+		Host:     host,
+		Port:     port,
+		User:     user,
+		Database: dbName,
+		SSLMode:  connectionSSLMode,
+		URI: fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=%s",
+			url.PathEscape(user), passwordPlaceholder, host, port, url.PathEscape(dbName), connectionSSLMode),
+		KeyValue: keyValue,
+		JDBC: fmt.Sprintf("jdbc:postgresql://%s:%s/%s?user=%s&password=%s&sslmode=%s",
+			host, port, url.PathEscape(dbName), url.QueryEscape(user), passwordPlaceholder, connectionSSLMode),
+		Go: fmt.Sprintf("sql.Open(%q, %q)", connectionDriverName, keyValue),
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+	})
+}
+
+func quoteKeyValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " '\\") {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"config-service/compute-tier"
 	"config-service/dto"
 	"config-service/models"
 	"config-service/node-info"
 	"log"
 	"net/http"
 	"net/rpc"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,18 +19,100 @@ import (
 )
 
 type CreateDatabasePayload struct {
-	Name     string
-	Type     string
-	Version  string
-	Password string
-	User     string
-	UUID     uuid.UUID
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	User         string
+	UUID         uuid.UUID
+	Limits       ComputeLimits
+	StorageMB    int64
+	Connectivity string
+}
+
+type ComputeLimits struct {
+	CPU            float64
+	MemoryMB       int64
+	MaxConnections int
 }
 
 type CreateDatabaseResponse struct {
-	Status   string
-	NodeIP   string
-	NodePort string
+	Status     string
+	NodeIP     string
+	NodePort   string
+	VolumePath string
+	Error      string
+}
+
+type DeleteDatabasePayload struct {
+	UUID         uuid.UUID
+	Type         string
+	GrafanaUID   string
+	RetainVolume bool
+}
+
+type DeleteDatabaseResponse struct {
+	Status string
+}
+
+type ResizeDatabasePayload struct {
+	UUID   uuid.UUID
+	Limits ComputeLimits
+}
+
+type ResizeDatabaseResponse struct {
+	Status string
+}
+
+type StoragePayload struct {
+	UUID      uuid.UUID
+	StorageMB int64
+}
+
+type StorageUsageResponse struct {
+	Status     string
+	UsedBytes  int64
+	TotalBytes int64
+}
+
+type GrowStorageResponse struct {
+	Status string
+}
+
+type PurgeVolumePayload struct {
+	UUID uuid.UUID
+}
+
+type PurgeVolumeResponse struct {
+	Status string
+}
+
+type PowerStatePayload struct {
+	UUID uuid.UUID
+}
+
+type PowerStateResponse struct {
+	Status string
+}
+
+const storageLimitPercentage float64 = 95
+
+var storageUnitMB = map[string]int64{
+	"MB": 1,
+	"GB": 1024,
+	"TB": 1024 * 1024,
+}
+
+// inFlightStatuses are the ones an operation is still running in, nothing else
+// may touch the deployment until it's done.
+var inFlightStatuses = map[string]bool{
+	models.StatusDeleting:   true,
+	models.StatusStopping:   true,
+	models.StatusStarting:   true,
+	models.StatusRestarting: true,
+	models.StatusResizing:   true,
+	models.StatusUpgrading:  true,
+	models.StatusMigrating:  true,
 }
 
 func CreateServer(c *gin.Context) {
@@ -73,50 +158,37 @@ func CreateDatabase(c *gin.Context) {
 		return
 	}
 
-	server, err := models.DB.ServerEntry.GetOne(databaseDto.Server)
-	if err != nil {
-		c.AbortWithStatus(http.StatusInternalServerError)
+	tier, exists := compute.GetTier(databaseDto.ServiceType, databaseDto.ComputeType)
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown service type or compute type"})
 		return
 	}
 
-	directoryUUID := uuid.New()
-	go createDatabase(databaseDto, server, directoryUUID)
-
-	c.JSON(http.StatusCreated, gin.H{
-		"uuid": directoryUUID.String(),
-	})
-}
-
-func createDatabase(databaseDto dto.DatabaseDto, server *models.ServerEntry, directoryUUID uuid.UUID) {
-
-	var reply CreateDatabaseResponse
-	payload := CreateDatabasePayload{
-		Name:     databaseDto.Name,
-		Type:     databaseDto.Type,
-		Version:  databaseDto.Version,
-		User:     server.Admin,
-		Password: databaseDto.Password,
-		UUID:     directoryUUID,
+	storageMB, valid := storageSizeMB(databaseDto.MaxStorageSize, databaseDto.StorageSizeUnit)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max storage size or storage size unit"})
+		return
 	}
 
-	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	server, err := models.DB.ServerEntry.GetOne(databaseDto.Server)
 	if err != nil {
-		log.Println("Error when dialing node rpc")
+		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	err = client.Call("RPCServer.CreateDatabase", payload, &reply)
-	if err != nil {
-		log.Println("Error when calling node rpc")
+	if _, err := models.DB.DatabaseEntry.GetOne(databaseDto.Name, databaseDto.Email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database " + databaseDto.Name + " already exists"})
 		return
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(databaseDto.Password), 10)
 	if err != nil {
-		log.Println("Error: Failed to hash the password")
+		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
+	directoryUUID := uuid.New()
+
 	database := models.DatabaseEntry{
 		Name:        databaseDto.Name,
 		Password:    string(hash),
@@ -131,20 +203,93 @@ func createDatabase(databaseDto dto.DatabaseDto, server *models.ServerEntry, dir
 		Connectivity:  databaseDto.Connectivity,
 		Type:          databaseDto.Type,
 		Version:       databaseDto.Version,
-		NodeIP:        strings.Split(reply.NodeIP, ":")[0],
-		NodePort:      reply.NodePort,
 		DirectoryUUID: directoryUUID.String(),
 		GrafanaUID:    "",
 		Email:         databaseDto.Email,
 		CreatedAt:     time.Now(),
-		Status:        "ONLINE",
+		Status:        models.StatusProvisioning,
+		PasswordSetAt: time.Now(),
+		Provisioning: &models.Provisioning{
+			State: models.StatusProvisioning,
+			Steps: []models.ProvisioningStep{
+				{Name: models.StepAccepted, At: time.Now()},
+			},
+		},
 	}
 
 	err = models.DB.DatabaseEntry.Insert(database)
 	if err != nil {
-		log.Println("Error: Failed to insert new database entry")
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	go createDatabase(databaseDto, server, directoryUUID, tier, storageMB)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"uuid": directoryUUID.String(),
+	})
+}
+
+func createDatabase(databaseDto dto.DatabaseDto, server *models.ServerEntry, directoryUUID uuid.UUID, tier compute.Tier, storageMB int64) {
+
+	var reply CreateDatabaseResponse
+	payload := CreateDatabasePayload{
+		Name:         databaseDto.Name,
+		Type:         databaseDto.Type,
+		Version:      databaseDto.Version,
+		User:         server.Admin,
+		Password:     databaseDto.Password,
+		UUID:         directoryUUID,
+		Limits:       ComputeLimits(tier),
+		StorageMB:    storageMB,
+		Connectivity: databaseDto.Connectivity,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.FailProvisioning(directoryUUID.String(), "Can't reach node for server "+server.Name)
+		return
+	}
+	defer client.Close()
+
+	err = models.DB.DatabaseEntry.AddProvisioningStep(directoryUUID.String(), models.StepNodeRequested)
+	if err != nil {
+		models.DB.DatabaseEntry.FailProvisioning(directoryUUID.String(), "Failed to record provisioning progress")
+		return
+	}
+
+	err = client.Call("RPCServer.CreateDatabase", payload, &reply)
+	finishProvisioning(directoryUUID.String(), reply, err)
+}
+
+func finishProvisioning(directoryUUID string, reply CreateDatabaseResponse, err error) {
+
+	if err != nil {
+		log.Println("Error when calling node rpc")
+		models.DB.DatabaseEntry.FailProvisioning(directoryUUID, "Node call failed: "+err.Error())
+		return
+	}
+
+	if reply.Status == "CANCELLED" {
+		models.DB.DatabaseEntry.CancelProvisioning(directoryUUID)
 		return
 	}
+
+	if reply.Status != "CREATED" {
+		reason := "Node failed to provision the deployment"
+		if reply.Error != "" {
+			reason += ": " + reply.Error
+		}
+
+		models.DB.DatabaseEntry.FailProvisioning(directoryUUID, reason)
+		return
+	}
+
+	err = models.DB.DatabaseEntry.FinishProvisioning(directoryUUID, strings.Split(reply.NodeIP, ":")[0], reply.NodePort, reply.VolumePath)
+	if err != nil {
+		log.Println("Error: Failed to finish provisioning of database entry")
+	}
 }
 
 func DbOverviewByName(c *gin.Context) {
@@ -174,6 +319,40 @@ func DbOverviewByName(c *gin.Context) {
 		Configuration: dto.ConfigurationDto(database.Configuration),
 		NodeIP:        database.NodeIP,
 		NodePort:      database.NodePort,
+		PasswordSetAt: database.PasswordSetAt,
+	}
+
+	if database.Previous != nil {
+		response.Previous = database.Previous.Version
+	}
+
+	if database.Provisioning != nil {
+		response.Provisioning = &dto.ProvisioningDto{
+			State:  database.Provisioning.State,
+			Reason: database.Provisioning.Reason,
+		}
+
+		for _, step := range database.Provisioning.Steps {
+			response.Provisioning.Steps = append(response.Provisioning.Steps, dto.ProvisioningStepDto(step))
+		}
+	}
+
+	replicas, err := models.DB.ReplicaEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	response.Replicas = replicaDtos(replicas)
+
+	if database.Migration != nil {
+		response.Migration = &dto.MigrationDto{
+			SourceServer: database.Migration.SourceServer,
+			TargetServer: database.Migration.TargetServer,
+			State:        database.Migration.State,
+			Reason:       database.Migration.Reason,
+			StartedAt:    database.Migration.StartedAt,
+			FinishedAt:   database.Migration.FinishedAt,
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -181,95 +360,696 @@ func DbOverviewByName(c *gin.Context) {
 	})
 }
 
-func UserDatabases(c *gin.Context) {
+func DeleteDatabase(c *gin.Context) {
 	email := c.Param("email")
+	name := c.Param("name")
 
-	databases, err := models.DB.DatabaseEntry.GetAllByEmail(email)
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status == models.StatusProvisioning {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database is still being provisioned"})
+		return
+	}
+
+	if inFlightStatuses[database.Status] {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database is " + database.Status + ", wait for it to finish"})
+		return
+	}
+
+	if database.Previous != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Confirm or roll back the pending upgrade first"})
+		return
+	}
+
+	replicas, err := models.DB.ReplicaEntry.GetAllByDatabase(database.ID.Hex())
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	var response []dto.ResourceDto
+	for _, replica := range replicas {
+		if replica.Status == models.StatusProvisioning {
+			c.JSON(http.StatusConflict, gin.H{"error": "Replica " + replica.DirectoryUUID + " is still being provisioned"})
+			return
+		}
+	}
 
-	for _, v := range databases {
+	if database.Status == models.StatusFailed || database.Status == models.StatusCancelled {
+		discardFailedDeployment(database)
 
-		server, err := models.DB.ServerEntry.GetOne(v.Server)
+		err = models.DB.DatabaseEntry.Delete(database.DirectoryUUID)
 		if err != nil {
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
 
-		resource := dto.ResourceDto{
-			Name:     v.Name,
-			Location: server.Location,
-			Server:   v.Server,
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := models.DB.DatabaseEntry.UpdateStatusIf(database.DirectoryUUID, database.Status, models.StatusDeleting)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database status changed, try again"})
+		return
+	}
+
+	retainVolume := c.Query("retain_volume") == "true"
+
+	var reply DeleteDatabaseResponse
+	payload := DeleteDatabasePayload{
+		UUID:         directoryUUID,
+		Type:         database.Type,
+		GrafanaUID:   database.GrafanaUID,
+		RetainVolume: retainVolume,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = client.Call("RPCServer.DeleteDatabase", payload, &reply)
+	if err != nil || reply.Status != "DELETED" {
+		log.Println("Error when calling node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete database"})
+		return
+	}
+
+	for _, replica := range replicas {
+		err = deleteReplica(client, replica, database.Type)
+		if err != nil {
+			log.Println("Error deleting replica", replica.DirectoryUUID, "of deleted database")
+			models.DB.ReplicaEntry.Delete(replica.DirectoryUUID)
 		}
+	}
 
-		response = append(response, resource)
+	if retainVolume {
+		volume := models.VolumeEntry{
+			DirectoryUUID: database.DirectoryUUID,
+			Database:      database.Name,
+			Server:        database.Server,
+			NodeIP:        database.NodeIP,
+			Path:          database.VolumePath,
+			Email:         database.Email,
+			RetainedAt:    time.Now(),
+		}
+
+		err = models.DB.VolumeEntry.Insert(volume)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"response": response,
-	})
+	err = models.DB.DatabaseEntry.Delete(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
 }
 
-func UserServers(c *gin.Context) {
-	email := c.Param("email")
+// discardFailedDeployment removes what a failed or cancelled provisioning left
+// on the node, the node call may have failed after Terraform already ran.
+func discardFailedDeployment(database *models.DatabaseEntry) {
 
-	servers, err := models.DB.ServerEntry.GetAll(email)
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
 	if err != nil {
-		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	var response []dto.ServerResponseDto
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		return
+	}
 
-	for _, v := range servers {
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		return
+	}
+	defer client.Close()
 
-		resource := dto.ServerResponseDto{
-			Name:     v.Name,
-			Location: v.Location,
-		}
+	var reply DeleteDatabaseResponse
+	payload := DeleteDatabasePayload{
+		UUID:       directoryUUID,
+		Type:       database.Type,
+		GrafanaUID: database.GrafanaUID,
+	}
 
-		response = append(response, resource)
+	err = client.Call("RPCServer.DeleteDatabase", payload, &reply)
+	if err != nil || reply.Status != "DELETED" {
+		log.Println("Nothing removed on the node for failed deployment", database.DirectoryUUID)
+	}
+}
+
+func UserVolumes(c *gin.Context) {
+	email := c.Param("email")
+
+	volumes, err := models.DB.VolumeEntry.GetAllByEmail(email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"response": response,
+		"response": volumes,
 	})
 }
 
-func DbGrafanaUIDByName(c *gin.Context) {
+func PurgeVolume(c *gin.Context) {
 	email := c.Param("email")
-	name := c.Param("name")
 
-	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	volume, err := models.DB.VolumeEntry.GetOne(c.Param("uuid"), email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(volume.Server)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	response := dto.DatabaseGrafanaDto{
-		GrafanaUID:    database.GrafanaUID,
-		DirectoryUUID: database.DirectoryUUID,
+	directoryUUID, err := uuid.Parse(volume.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"response": response,
-	})
-}
+	var reply PurgeVolumeResponse
+	payload := PurgeVolumePayload{
+		UUID: directoryUUID,
+	}
 
-func UpdateGrafanaUIDByDirectoryUUID(c *gin.Context) {
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
 
-	var setGrafanaDto dto.SetGrafanaDto
+	err = client.Call("RPCServer.PurgeVolume", payload, &reply)
+	if err != nil || reply.Status != "PURGED" {
+		log.Println("Error when calling node rpc")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge volume"})
+		return
+	}
 
-	if err := c.BindJSON(&setGrafanaDto); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+	err = models.DB.VolumeEntry.Delete(volume.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	directoryUUID := c.Param("directoryUUID")
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func ResizeDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var resizeDto dto.ResizeDto
+
+	if err := c.BindJSON(&resizeDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	configuration := database.Configuration
+	if resizeDto.ServiceType != "" {
+		configuration.ServiceType = resizeDto.ServiceType
+	}
+	configuration.ComputeType = resizeDto.ComputeType
+
+	tier, exists := compute.GetTier(configuration.ServiceType, configuration.ComputeType)
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown service type or compute type"})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := models.DB.DatabaseEntry.UpdateStatusIf(database.DirectoryUUID, models.StatusOnline, models.StatusResizing)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database status changed, try again"})
+		return
+	}
+
+	var reply ResizeDatabaseResponse
+	payload := ResizeDatabasePayload{
+		UUID:   directoryUUID,
+		Limits: ComputeLimits(tier),
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = client.Call("RPCServer.ResizeDatabase", payload, &reply)
+	if err != nil || reply.Status != "RESIZED" {
+		log.Println("Error when calling node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resize database"})
+		return
+	}
+
+	err = models.DB.DatabaseEntry.UpdateConfiguration(database.DirectoryUUID, configuration)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, models.StatusOnline)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": dto.ConfigurationDto(configuration),
+	})
+}
+
+func StorageUsage(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var reply StorageUsageResponse
+	payload := StoragePayload{
+		UUID: directoryUUID,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = client.Call("RPCServer.StorageUsage", payload, &reply)
+	if err != nil || reply.Status != "OK" {
+		log.Println("Error when calling node rpc")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read storage usage"})
+		return
+	}
+
+	var usedPercentage float64
+	if reply.TotalBytes > 0 {
+		usedPercentage = float64(reply.UsedBytes) * 100 / float64(reply.TotalBytes)
+	}
+
+	response := dto.StorageUsageDto{
+		UsedBytes:      reply.UsedBytes,
+		TotalBytes:     reply.TotalBytes,
+		UsedPercentage: usedPercentage,
+		LimitReached:   usedPercentage >= storageLimitPercentage,
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+	})
+}
+
+func GrowStorage(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var storageDto dto.StorageDto
+
+	if err := c.BindJSON(&storageDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	storageMB, valid := storageSizeMB(storageDto.MaxStorageSize, storageDto.StorageSizeUnit)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max storage size or storage size unit"})
+		return
+	}
+
+	currentStorageMB, valid := storageSizeMB(database.Configuration.MaxStorageSize, database.Configuration.StorageSizeUnit)
+	if valid && storageMB <= currentStorageMB {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Storage can only be grown"})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := models.DB.DatabaseEntry.UpdateStatusIf(database.DirectoryUUID, models.StatusOnline, models.StatusResizing)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database status changed, try again"})
+		return
+	}
+
+	var reply GrowStorageResponse
+	payload := StoragePayload{
+		UUID:      directoryUUID,
+		StorageMB: storageMB,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = client.Call("RPCServer.GrowStorage", payload, &reply)
+	if err != nil || reply.Status != "GROWN" {
+		log.Println("Error when calling node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grow storage"})
+		return
+	}
+
+	configuration := database.Configuration
+	configuration.MaxStorageSize = storageDto.MaxStorageSize
+	configuration.StorageSizeUnit = storageDto.StorageSizeUnit
+
+	err = models.DB.DatabaseEntry.UpdateConfiguration(database.DirectoryUUID, configuration)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, models.StatusOnline)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": dto.ConfigurationDto(configuration),
+	})
+}
+
+func storageSizeMB(size string, unit string) (int64, bool) {
+
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+
+	multiplier, exists := storageUnitMB[strings.ToUpper(unit)]
+	if !exists {
+		return 0, false
+	}
+
+	return int64(value * float64(multiplier)), true
+}
+
+func ComputeTiers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"response": compute.TierMp,
+	})
+}
+
+func StopDatabase(c *gin.Context) {
+	changePowerState(c, "RPCServer.StopDatabase", models.StatusStopping, models.StatusStopped, models.StatusOnline)
+}
+
+func StartDatabase(c *gin.Context) {
+	changePowerState(c, "RPCServer.StartDatabase", models.StatusStarting, models.StatusOnline, models.StatusStopped, models.StatusDegraded)
+}
+
+func RestartDatabase(c *gin.Context) {
+	changePowerState(c, "RPCServer.RestartDatabase", models.StatusRestarting, models.StatusOnline, models.StatusOnline, models.StatusDegraded)
+}
+
+func changePowerState(c *gin.Context, method string, transitionStatus string, finalStatus string, requiredStatuses ...string) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if !slices.Contains(requiredStatuses, database.Status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + strings.Join(requiredStatuses, " or ") + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := models.DB.DatabaseEntry.UpdateStatusIf(database.DirectoryUUID, database.Status, transitionStatus)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database status changed, try again"})
+		return
+	}
+
+	var reply PowerStateResponse
+	payload := PowerStatePayload{
+		UUID: directoryUUID,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = client.Call(method, payload, &reply)
+	if err != nil || reply.Status != finalStatus {
+		log.Println("Error when calling node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change database power state"})
+		return
+	}
+
+	err = models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, finalStatus)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": finalStatus,
+	})
+}
+
+func UserDatabases(c *gin.Context) {
+	email := c.Param("email")
+
+	databases, err := models.DB.DatabaseEntry.GetAllByEmail(email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var response []dto.ResourceDto
+
+	for _, v := range databases {
+
+		server, err := models.DB.ServerEntry.GetOne(v.Server)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		resource := dto.ResourceDto{
+			Name:     v.Name,
+			Location: server.Location,
+			Server:   v.Server,
+		}
+
+		response = append(response, resource)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+	})
+}
+
+func UserServers(c *gin.Context) {
+	email := c.Param("email")
+
+	servers, err := models.DB.ServerEntry.GetAll(email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var response []dto.ServerResponseDto
+
+	for _, v := range servers {
+
+		resource := dto.ServerResponseDto{
+			Name:     v.Name,
+			Location: v.Location,
+		}
+
+		response = append(response, resource)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+	})
+}
+
+func DbGrafanaUIDByName(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	response := dto.DatabaseGrafanaDto{
+		GrafanaUID:    database.GrafanaUID,
+		DirectoryUUID: database.DirectoryUUID,
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+	})
+}
+
+func UpdateGrafanaUIDByDirectoryUUID(c *gin.Context) {
+
+	var setGrafanaDto dto.SetGrafanaDto
+
+	if err := c.BindJSON(&setGrafanaDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	directoryUUID := c.Param("directoryUUID")
 
 	err := models.DB.DatabaseEntry.UpdateGrafanaUID(directoryUUID, setGrafanaDto.GrafanaUID)
 	if err != nil {
@@ -277,5 +1057,11 @@ func UpdateGrafanaUIDByDirectoryUUID(c *gin.Context) {
 		return
 	}
 
+	err = models.DB.ReplicaEntry.UpdateGrafanaUID(directoryUUID, setGrafanaDto.GrafanaUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{})
 }
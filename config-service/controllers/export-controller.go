@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"config-service/models"
+	"config-service/node-info"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+const exportErrorHeader = "X-Export-Error"
+
+var exportExtensions = map[string]string{
+	"custom":        ".dump",
+	"plain":         ".sql",
+	"directory-tar": ".tar",
+}
+
+func ExportDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	format := c.DefaultQuery("format", "custom")
+	extension, ok := exportExtensions[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be one of custom, plain or directory-tar"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	query := url.Values{
+		"format":        {format},
+		"table":         c.QueryArray("table"),
+		"exclude_table": c.QueryArray("exclude_table"),
+	}
+
+	exportUrl := "http://" + node.LocationServerMp[server.Location] + "/exports/" + database.DirectoryUUID + "?" + query.Encode()
+	request, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, exportUrl, nil)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Export failed: " + string(body)})
+		return
+	}
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", "attachment; filename=\""+database.Name+extension+"\"")
+	c.Header("Trailer", exportErrorHeader)
+	c.Status(http.StatusOK)
+
+	_, err = io.Copy(c.Writer, response.Body)
+	if err != nil {
+		c.Header(exportErrorHeader, "Export was interrupted")
+		return
+	}
+
+	if exportError := response.Trailer.Get(exportErrorHeader); exportError != "" {
+		c.Header(exportErrorHeader, exportError)
+	}
+}
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"io"
+	"log"
+	"net/http"
+	"net/rpc"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	importFilenameHeader   = "X-Filename"
+	importSizeHeader       = "X-Upload-Size"
+	importProgressInterval = 2 * time.Second
+)
+
+type ImportDatabasePayload struct {
+	UUID    uuid.UUID
+	JobUUID uuid.UUID
+}
+
+type ImportDatabaseResponse struct {
+	Status string
+	Format string
+	Error  string
+}
+
+// progressReader records how much of an upload went through, throttled so a
+// fast upload doesn't turn into a write per chunk.
+type progressReader struct {
+	reader    io.Reader
+	jobUUID   string
+	bytes     int64
+	updatedAt time.Time
+}
+
+func (p *progressReader) Read(data []byte) (int, error) {
+	n, err := p.reader.Read(data)
+	p.bytes += int64(n)
+
+	if time.Since(p.updatedAt) >= importProgressInterval {
+		p.updatedAt = time.Now()
+		models.DB.ImportEntry.UpdateProgress(p.jobUUID, p.bytes)
+	}
+
+	return n, err
+}
+
+func ImportDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	imports, err := models.DB.ImportEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	for _, job := range imports {
+		if job.Status == models.ImportUploading || job.Status == models.ImportRestoring {
+			c.JSON(http.StatusConflict, gin.H{"error": "Import " + job.JobUUID + " is still in progress"})
+			return
+		}
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	totalBytes, err := strconv.ParseInt(c.GetHeader(importSizeHeader), 10, 64)
+	if err != nil {
+		totalBytes = c.Request.ContentLength
+	}
+
+	job := models.ImportEntry{
+		JobUUID:       uuid.New().String(),
+		DatabaseID:    database.ID.Hex(),
+		DirectoryUUID: database.DirectoryUUID,
+		Database:      database.Name,
+		Email:         email,
+		Filename:      c.GetHeader(importFilenameHeader),
+		Status:        models.ImportUploading,
+		TotalBytes:    totalBytes,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	err = models.DB.ImportEntry.Insert(job)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	upload := &progressReader{reader: c.Request.Body, jobUUID: job.JobUUID, updatedAt: time.Now()}
+
+	url := "http://" + node.LocationServerMp[server.Location] + "/imports/" + database.DirectoryUUID + "/" + job.JobUUID
+	request, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPut, url, upload)
+	if err != nil {
+		models.DB.ImportEntry.Fail(job.JobUUID, "Failed to prepare upload")
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		log.Println("Error uploading import to node:", err)
+		models.DB.ImportEntry.Fail(job.JobUUID, "Upload was interrupted")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Upload was interrupted", "uuid": job.JobUUID})
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusInsufficientStorage {
+		models.DB.ImportEntry.Fail(job.JobUUID, "Not enough storage for the uploaded file")
+		c.JSON(http.StatusInsufficientStorage, gin.H{"error": "Not enough storage for the uploaded file", "uuid": job.JobUUID})
+		return
+	}
+
+	if response.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(response.Body)
+		models.DB.ImportEntry.Fail(job.JobUUID, "Node rejected the upload: "+strings.TrimSpace(string(body)))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Node rejected the upload", "uuid": job.JobUUID})
+		return
+	}
+
+	err = models.DB.ImportEntry.StartRestore(job.JobUUID, upload.bytes)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	go runImport(job, server)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"uuid": job.JobUUID,
+	})
+}
+
+func runImport(job models.ImportEntry, server *models.ServerEntry) {
+
+	directoryUUID, err := uuid.Parse(job.DirectoryUUID)
+	if err != nil {
+		models.DB.ImportEntry.Fail(job.JobUUID, "Invalid deployment uuid")
+		return
+	}
+
+	jobUUID, err := uuid.Parse(job.JobUUID)
+	if err != nil {
+		models.DB.ImportEntry.Fail(job.JobUUID, "Invalid job uuid")
+		return
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.ImportEntry.Fail(job.JobUUID, "Can't reach node")
+		return
+	}
+	defer client.Close()
+
+	var reply ImportDatabaseResponse
+	payload := ImportDatabasePayload{
+		UUID:    directoryUUID,
+		JobUUID: jobUUID,
+	}
+
+	err = client.Call("RPCServer.ImportDatabase", payload, &reply)
+	if err != nil {
+		log.Println("Error when calling node rpc")
+		models.DB.ImportEntry.Fail(job.JobUUID, err.Error())
+		return
+	}
+
+	if reply.Status != "IMPORTED" {
+		models.DB.ImportEntry.Fail(job.JobUUID, reply.Error)
+		return
+	}
+
+	models.DB.ImportEntry.Complete(job.JobUUID, reply.Format)
+}
+
+func importDto(job *models.ImportEntry) dto.ImportDto {
+
+	percent := 0
+	switch {
+	case job.Status == models.ImportCompleted:
+		percent = 100
+	case job.TotalBytes > 0:
+		percent = int(min(job.ReceivedBytes*100/job.TotalBytes, 99))
+	}
+
+	return dto.ImportDto{
+		UUID:          job.JobUUID,
+		Database:      job.Database,
+		Filename:      job.Filename,
+		Format:        job.Format,
+		Status:        job.Status,
+		TotalBytes:    job.TotalBytes,
+		ReceivedBytes: job.ReceivedBytes,
+		Percent:       percent,
+		CreatedAt:     job.CreatedAt,
+		UpdatedAt:     job.UpdatedAt,
+		FinishedAt:    job.FinishedAt,
+		Error:         job.Error,
+	}
+}
+
+func DatabaseImports(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	imports, err := models.DB.ImportEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	response := []dto.ImportDto{}
+	for _, job := range imports {
+		response = append(response, importDto(job))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": response,
+	})
+}
+
+func DatabaseImport(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	job, err := models.DB.ImportEntry.GetOne(c.Param("import"), email)
+	if err != nil || job.DatabaseID != database.ID.Hex() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": importDto(job),
+	})
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"bytes"
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/rpc"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	connectivityPublic  = "Public"
+	connectivityPrivate = "Private"
+)
+
+type UpdateDatabasePayload struct {
+	UUID         uuid.UUID
+	Type         string
+	GrafanaUID   string
+	Name         string
+	Connectivity string
+}
+
+type UpdateDatabaseResponse struct {
+	Status string
+}
+
+var databaseNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]{0,62}$`)
+
+var immutableFields = map[string]string{
+	"password":          "use PUT /users/databases/:name/password",
+	"server":            "clone the database to another server instead",
+	"type":              "database type cannot be changed",
+	"version":           "use POST /users/databases/:name/upgrade",
+	"service_type":      "use POST /users/databases/:name/resize",
+	"compute_type":      "use POST /users/databases/:name/resize",
+	"max_storage_size":  "use PATCH /users/databases/:name/storage",
+	"storage_size_unit": "use PATCH /users/databases/:name/storage",
+	"email":             "database owner cannot be changed",
+}
+
+var mutableFields = map[string]bool{
+	"name":         true,
+	"environment":  true,
+	"connectivity": true,
+}
+
+func UpdateDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	for field := range fields {
+		if reason, exists := immutableFields[field]; exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Field " + field + " cannot be changed: " + reason})
+			return
+		}
+
+		if !mutableFields[field] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown field " + field})
+			return
+		}
+	}
+
+	var updateDto dto.UpdateDatabaseDto
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&updateDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fields name, environment and connectivity must be strings"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	newName := database.Name
+	environment := database.Environment
+	connectivity := database.Connectivity
+
+	if updateDto.Name != nil {
+		if !databaseNameRegex.MatchString(*updateDto.Name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name must start with a letter or underscore and contain at most 63 letters, digits, underscores or hyphens"})
+			return
+		}
+
+		if *updateDto.Name != database.Name {
+			if _, err := models.DB.DatabaseEntry.GetOne(*updateDto.Name, email); err == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": "Database " + *updateDto.Name + " already exists"})
+				return
+			}
+		}
+
+		newName = *updateDto.Name
+	}
+
+	if updateDto.Environment != nil {
+		if *updateDto.Environment == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Environment cannot be empty"})
+			return
+		}
+
+		environment = *updateDto.Environment
+	}
+
+	if updateDto.Connectivity != nil {
+		if *updateDto.Connectivity != connectivityPublic && *updateDto.Connectivity != connectivityPrivate {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Connectivity must be " + connectivityPublic + " or " + connectivityPrivate})
+			return
+		}
+
+		connectivity = *updateDto.Connectivity
+	}
+
+	nodeChange := newName != database.Name || connectivity != database.Connectivity
+
+	if nodeChange {
+		if database.Status != models.StatusOnline {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+			})
+			return
+		}
+
+		err = updateNodeDatabase(database, newName, connectivity)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply changes on the node"})
+			return
+		}
+	}
+
+	err = models.DB.DatabaseEntry.UpdateMetadata(database.DirectoryUUID, newName, environment, connectivity)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func updateNodeDatabase(database *models.DatabaseEntry, name string, connectivity string) error {
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		return err
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		return err
+	}
+
+	payload := UpdateDatabasePayload{
+		UUID:       directoryUUID,
+		Type:       database.Type,
+		GrafanaUID: database.GrafanaUID,
+	}
+
+	if name != database.Name {
+		payload.Name = name
+	}
+
+	if connectivity != database.Connectivity {
+		payload.Connectivity = connectivity
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		return err
+	}
+	defer client.Close()
+
+	var reply UpdateDatabaseResponse
+	err = client.Call("RPCServer.UpdateDatabase", payload, &reply)
+	if err != nil || reply.Status != "UPDATED" {
+		log.Println("Error when calling node rpc")
+		return errors.New("node failed to update database")
+	}
+
+	return nil
+}
@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"config-service/compute-tier"
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"errors"
+	"log"
+	"net/http"
+	"net/rpc"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SetReadOnlyPayload struct {
+	UUID     uuid.UUID
+	ReadOnly bool
+}
+
+type SetReadOnlyResponse struct {
+	Status string
+}
+
+type DeploymentCredentialsPayload struct {
+	UUID uuid.UUID
+}
+
+type DeploymentCredentialsResponse struct {
+	Status   string
+	Name     string
+	User     string
+	Password string
+}
+
+type MigrateDatabasePayload struct {
+	UUID         uuid.UUID
+	SourceURL    string
+	Name         string
+	User         string
+	Password     string
+	Type         string
+	Version      string
+	Limits       ComputeLimits
+	StorageMB    int64
+	Connectivity string
+}
+
+type CompleteMigrationPayload struct {
+	UUID uuid.UUID
+	Type string
+}
+
+type CompleteMigrationResponse struct {
+	Status string
+}
+
+func MigrateDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var migrateDto dto.MigrateDto
+
+	if err := c.BindJSON(&migrateDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Previous != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Confirm or roll back the pending upgrade first"})
+		return
+	}
+
+	replicas, err := models.DB.ReplicaEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if len(replicas) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delete the replicas of the database first"})
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	if migrateDto.Server == database.Server {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Database is already on server " + database.Server})
+		return
+	}
+
+	sourceServer, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	targetServer, err := models.DB.ServerEntry.GetOne(migrateDto.Server)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown server " + migrateDto.Server})
+		return
+	}
+
+	if node.LocationServerMp[targetServer.Location] == node.LocationServerMp[sourceServer.Location] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Server " + targetServer.Name + " runs on the same node"})
+		return
+	}
+
+	newUUID := uuid.New()
+
+	started, err := models.DB.DatabaseEntry.StartMigration(database.DirectoryUUID, models.Migration{
+		DirectoryUUID: newUUID.String(),
+		SourceServer:  sourceServer.Name,
+		TargetServer:  targetServer.Name,
+		State:         models.MigrationInProgress,
+		StartedAt:     time.Now(),
+	})
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !started {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database status changed, try again"})
+		return
+	}
+
+	tier, _ := compute.GetTier(database.Configuration.ServiceType, database.Configuration.ComputeType)
+	storageMB, _ := storageSizeMB(database.Configuration.MaxStorageSize, database.Configuration.StorageSizeUnit)
+
+	go migrateDatabase(database, sourceServer, targetServer, newUUID, tier, storageMB)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"uuid": newUUID.String(),
+	})
+}
+
+// migrateDatabase copies a database to another node and cuts over to it. The
+// source is read only while its dump is loaded on the target, so nothing
+// written in the meantime is lost.
+func migrateDatabase(database *models.DatabaseEntry, sourceServer *models.ServerEntry, targetServer *models.ServerEntry, newUUID uuid.UUID, tier compute.Tier, storageMB int64) {
+
+	sourceUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, "Invalid deployment uuid")
+		return
+	}
+
+	sourceClient, err := rpc.DialHTTP("tcp", node.LocationServerMp[sourceServer.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, "Can't reach node for server "+sourceServer.Name)
+		return
+	}
+	defer sourceClient.Close()
+
+	var credentials DeploymentCredentialsResponse
+	err = sourceClient.Call("RPCServer.DeploymentCredentials", DeploymentCredentialsPayload{UUID: sourceUUID}, &credentials)
+	if err != nil || credentials.Status != "OK" {
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, "Can't read the source deployment")
+		return
+	}
+
+	err = setReadOnly(sourceClient, sourceUUID, true)
+	if err != nil {
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, "Can't make the source read only")
+		return
+	}
+
+	targetClient, err := rpc.DialHTTP("tcp", node.LocationServerMp[targetServer.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		abortMigration(sourceClient, sourceUUID, database.DirectoryUUID, "Can't reach node for server "+targetServer.Name)
+		return
+	}
+	defer targetClient.Close()
+
+	var reply CreateDatabaseResponse
+	payload := MigrateDatabasePayload{
+		UUID:         newUUID,
+		SourceURL:    "http://" + node.LocationServerMp[sourceServer.Location] + "/exports/" + database.DirectoryUUID + "?format=custom",
+		Name:         credentials.Name,
+		User:         credentials.User,
+		Password:     credentials.Password,
+		Type:         database.Type,
+		Version:      database.Version,
+		Limits:       ComputeLimits(tier),
+		StorageMB:    storageMB,
+		Connectivity: database.Connectivity,
+	}
+
+	err = targetClient.Call("RPCServer.MigrateDatabase", payload, &reply)
+	if err != nil {
+		log.Println("Error when calling node rpc")
+		abortMigration(sourceClient, sourceUUID, database.DirectoryUUID, "Node call failed: "+err.Error())
+		return
+	}
+
+	if reply.Status != "CREATED" {
+		abortMigration(sourceClient, sourceUUID, database.DirectoryUUID, "Copy on the target failed: "+reply.Error)
+		return
+	}
+
+	deployment := models.Deployment{
+		DirectoryUUID: newUUID.String(),
+		Version:       database.Version,
+		NodePort:      reply.NodePort,
+		VolumePath:    reply.VolumePath,
+	}
+
+	err = models.DB.DatabaseEntry.FinishMigration(database.DirectoryUUID, deployment, targetServer.Name, strings.Split(reply.NodeIP, ":")[0])
+	if err != nil {
+		log.Println("Error: Failed to switch database entry to migrated deployment")
+		abortMigration(sourceClient, sourceUUID, database.DirectoryUUID, "Failed to switch to the migrated deployment")
+
+		var deleteReply DeleteDatabaseResponse
+		err = targetClient.Call("RPCServer.DeleteDatabase", DeleteDatabasePayload{UUID: newUUID, Type: database.Type}, &deleteReply)
+		if err != nil || deleteReply.Status != "DELETED" {
+			log.Println("Error destroying target deployment", newUUID, "after failed migration")
+		}
+		return
+	}
+
+	var completeReply CompleteMigrationResponse
+	err = targetClient.Call("RPCServer.CompleteMigration", CompleteMigrationPayload{UUID: newUUID, Type: database.Type}, &completeReply)
+	if err != nil || completeReply.Status != "COMPLETED" {
+		log.Println("Error starting monitoring for migrated deployment", newUUID)
+	}
+
+	var deleteReply DeleteDatabaseResponse
+	err = sourceClient.Call("RPCServer.DeleteDatabase", DeleteDatabasePayload{
+		UUID:       sourceUUID,
+		Type:       database.Type,
+		GrafanaUID: database.GrafanaUID,
+	}, &deleteReply)
+	if err != nil || deleteReply.Status != "DELETED" {
+		log.Println("Error destroying source deployment", database.DirectoryUUID, "after migration")
+	}
+}
+
+func setReadOnly(client *rpc.Client, directoryUUID uuid.UUID, readOnly bool) error {
+
+	var reply SetReadOnlyResponse
+	err := client.Call("RPCServer.SetReadOnly", SetReadOnlyPayload{UUID: directoryUUID, ReadOnly: readOnly}, &reply)
+	if err != nil {
+		return err
+	}
+
+	if reply.Status != "OK" {
+		return errors.New("node failed to change read only mode")
+	}
+
+	return nil
+}
+
+func abortMigration(sourceClient *rpc.Client, sourceUUID uuid.UUID, directoryUUID string, reason string) {
+
+	err := setReadOnly(sourceClient, sourceUUID, false)
+	if err != nil {
+		log.Println("Error making", directoryUUID, "writable again after failed migration")
+		reason += ", and the source is still read only"
+	}
+
+	models.DB.DatabaseEntry.FailMigration(directoryUUID, reason)
+}
+
+// ResumeMigrations fails migrations cut short by a restart. The target copy is
+// cancelled and the source made writable again.
+func ResumeMigrations() {
+
+	entries, err := models.DB.DatabaseEntry.GetAllMigrating()
+	if err != nil {
+		log.Println("Error loading unfinished migrations")
+		return
+	}
+
+	for _, database := range entries {
+		go resumeMigration(database)
+	}
+}
+
+func resumeMigration(database *models.DatabaseEntry) {
+
+	reason := "Interrupted by a config-service restart"
+
+	if database.Migration == nil {
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, reason)
+		return
+	}
+
+	targetServer, err := models.DB.ServerEntry.GetOne(database.Migration.TargetServer)
+	if err == nil {
+		targetUUID, _ := uuid.Parse(database.Migration.DirectoryUUID)
+
+		targetClient, err := rpc.DialHTTP("tcp", node.LocationServerMp[targetServer.Location])
+		if err == nil {
+			var reply CancelDeploymentResponse
+			targetClient.Call("RPCServer.CancelDeployment", CancelDeploymentPayload{UUID: targetUUID}, &reply)
+			targetClient.Close()
+		}
+	}
+
+	sourceServer, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, reason)
+		return
+	}
+
+	sourceUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, reason)
+		return
+	}
+
+	sourceClient, err := rpc.DialHTTP("tcp", node.LocationServerMp[sourceServer.Location])
+	if err != nil {
+		models.DB.DatabaseEntry.FailMigration(database.DirectoryUUID, reason+", and the source may still be read only")
+		return
+	}
+	defer sourceClient.Close()
+
+	abortMigration(sourceClient, sourceUUID, database.DirectoryUUID, reason)
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"crypto/rand"
+	"log"
+	"math/big"
+	"net/http"
+	"net/rpc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	generatedPasswordLength = 24
+	passwordAlphabet        = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+type ChangePasswordPayload struct {
+	UUID     uuid.UUID
+	Password string
+}
+
+type ChangePasswordResponse struct {
+	Status  string
+	Warning string
+}
+
+func ChangePassword(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var passwordDto dto.PasswordDto
+
+	if err := c.BindJSON(&passwordDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	password := passwordDto.Password
+	generated := password == ""
+
+	if generated {
+		password, err = generatePassword()
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to hash the password",
+		})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var reply ChangePasswordResponse
+	payload := ChangePasswordPayload{
+		UUID:     directoryUUID,
+		Password: password,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = client.Call("RPCServer.ChangePassword", payload, &reply)
+	if err != nil || reply.Status != "CHANGED" {
+		log.Println("Error when calling node rpc")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change database password"})
+		return
+	}
+
+	err = models.DB.DatabaseEntry.UpdatePassword(database.DirectoryUUID, string(hash))
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	replicas, err := models.DB.ReplicaEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		log.Println("Error loading replicas to update their exporters")
+	}
+
+	for _, replica := range replicas {
+		replicaUUID, err := uuid.Parse(replica.DirectoryUUID)
+		if err != nil || replica.Status != models.StatusOnline {
+			continue
+		}
+
+		var replicaReply ChangePasswordResponse
+		err = client.Call("RPCServer.ChangePassword", ChangePasswordPayload{UUID: replicaUUID, Password: password}, &replicaReply)
+		if err != nil || replicaReply.Status != "CHANGED" {
+			log.Println("Error updating password of replica", replica.DirectoryUUID)
+		}
+	}
+
+	response := gin.H{}
+
+	if generated {
+		response["password"] = password
+	}
+
+	if reply.Warning != "" {
+		response["warning"] = reply.Warning
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func generatePassword() (string, error) {
+	password := make([]byte, generatedPasswordLength)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+
+	return string(password), nil
+}
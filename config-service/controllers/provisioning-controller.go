@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"config-service/compute-tier"
+	"config-service/models"
+	"config-service/node-info"
+	"log"
+	"net/http"
+	"net/rpc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ResumeDatabasePayload struct {
+	UUID   uuid.UUID
+	Type   string
+	Limits ComputeLimits
+}
+
+type CancelDeploymentPayload struct {
+	UUID uuid.UUID
+}
+
+type CancelDeploymentResponse struct {
+	Status string
+}
+
+func CancelDeployment(c *gin.Context) {
+	email := c.Param("email")
+
+	directoryUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deployment uuid"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetByDirectoryUUID(directoryUUID.String(), email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Status != models.StatusProvisioning {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Only " + models.StatusProvisioning + " deployments can be cancelled, current status is " + database.Status,
+		})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	var reply CancelDeploymentResponse
+	payload := CancelDeploymentPayload{
+		UUID: directoryUUID,
+	}
+
+	err = client.Call("RPCServer.CancelDeployment", payload, &reply)
+	if err != nil {
+		log.Println("Error when calling node rpc")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel deployment"})
+		return
+	}
+
+	if reply.Status != "CANCELLED" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Deployment is no longer running on the node"})
+		return
+	}
+
+	err = models.DB.DatabaseEntry.CancelProvisioning(directoryUUID.String())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func ResumeProvisioning() {
+
+	entries, err := models.DB.DatabaseEntry.GetAllProvisioning()
+	if err != nil {
+		log.Println("Error loading unfinished provisioning work")
+		return
+	}
+
+	for _, entry := range entries {
+		go resumeProvisioning(entry)
+	}
+}
+
+func resumeProvisioning(database *models.DatabaseEntry) {
+
+	steps := database.Provisioning.Steps
+	if len(steps) == 0 || steps[len(steps)-1].Name != models.StepNodeRequested {
+		models.DB.DatabaseEntry.FailProvisioning(database.DirectoryUUID, "Provisioning was interrupted before the request reached the node")
+		return
+	}
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		models.DB.DatabaseEntry.FailProvisioning(database.DirectoryUUID, "Invalid deployment uuid")
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		models.DB.DatabaseEntry.FailProvisioning(database.DirectoryUUID, "Unknown server "+database.Server)
+		return
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.FailProvisioning(database.DirectoryUUID, "Can't reach node for server "+server.Name)
+		return
+	}
+	defer client.Close()
+
+	log.Println("Resuming provisioning of deployment", database.DirectoryUUID)
+
+	tier, _ := compute.GetTier(database.Configuration.ServiceType, database.Configuration.ComputeType)
+
+	var reply CreateDatabaseResponse
+	payload := ResumeDatabasePayload{
+		UUID:   directoryUUID,
+		Type:   database.Type,
+		Limits: ComputeLimits(tier),
+	}
+
+	err = client.Call("RPCServer.ResumeDatabase", payload, &reply)
+	finishProvisioning(database.DirectoryUUID, reply, err)
+}
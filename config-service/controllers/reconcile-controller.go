@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"errors"
+	"log"
+	"net/http"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	reconcileInterval = 5 * time.Minute
+
+	containerRoleDatabase = "DATABASE"
+	containerRoleExporter = "EXPORTER"
+	containerRunning      = "running"
+)
+
+type ListContainersPayload struct{}
+
+type ContainerInfo struct {
+	Name           string
+	DeploymentUUID string
+	Role           string
+	State          string
+}
+
+type ListContainersResponse struct {
+	Status     string
+	Containers []ContainerInfo
+}
+
+var reconcileMtx sync.Mutex
+var reconcileReport dto.ReconcileReportDto
+
+var reconcilableStatuses = map[string]bool{
+	models.StatusOnline:   true,
+	models.StatusStopped:  true,
+	models.StatusDegraded: true,
+	models.StatusMissing:  true,
+}
+
+func Reconcile() {
+	for {
+		time.Sleep(reconcileInterval)
+
+		report, err := reconcile()
+		if err != nil {
+			log.Println("Error reconciling deployments:", err)
+			continue
+		}
+
+		reconcileMtx.Lock()
+		reconcileReport = report
+		reconcileMtx.Unlock()
+	}
+}
+
+func ReconcileReport(c *gin.Context) {
+	reconcileMtx.Lock()
+	report := reconcileReport
+	reconcileMtx.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"response": report,
+	})
+}
+
+func reconcile() (dto.ReconcileReportDto, error) {
+
+	report := dto.ReconcileReportDto{
+		CheckedAt: time.Now(),
+	}
+
+	servers, err := models.DB.ServerEntry.GetAllEntries()
+	if err != nil {
+		return report, err
+	}
+
+	databases, err := models.DB.DatabaseEntry.GetAllEntries()
+	if err != nil {
+		return report, err
+	}
+
+	replicas, err := models.DB.ReplicaEntry.GetAllEntries()
+	if err != nil {
+		return report, err
+	}
+
+	serverLocations := make(map[string]string)
+	for _, server := range servers {
+		serverLocations[server.Name] = server.Location
+	}
+
+	databasesByLocation := make(map[string][]*models.DatabaseEntry)
+	for _, database := range databases {
+		location := serverLocations[database.Server]
+		databasesByLocation[location] = append(databasesByLocation[location], database)
+	}
+
+	for location, address := range node.LocationServerMp {
+
+		containers, err := listContainers(address)
+		report.Nodes = append(report.Nodes, dto.NodeReportDto{Location: location, Reachable: err == nil})
+		if err != nil {
+			log.Println("Error listing containers on node", location, err)
+			continue
+		}
+
+		deployments := make(map[string]map[string]string)
+		for _, container := range containers {
+			if deployments[container.DeploymentUUID] == nil {
+				deployments[container.DeploymentUUID] = make(map[string]string)
+			}
+			deployments[container.DeploymentUUID][container.Role] = container.State
+		}
+
+		known := make(map[string]bool)
+		for _, replica := range replicas {
+			known[replica.DirectoryUUID] = true
+		}
+
+		for _, database := range databasesByLocation[location] {
+			known[database.DirectoryUUID] = true
+			if database.Previous != nil {
+				known[database.Previous.DirectoryUUID] = true
+			}
+
+			if !reconcilableStatuses[database.Status] {
+				continue
+			}
+
+			status := observedStatus(database.Status, deployments[database.DirectoryUUID])
+			if status == database.Status {
+				continue
+			}
+
+			updated, err := models.DB.DatabaseEntry.UpdateStatusIf(database.DirectoryUUID, database.Status, status)
+			if err != nil || !updated {
+				continue
+			}
+
+			log.Println("Deployment", database.DirectoryUUID, "drifted from", database.Status, "to", status)
+			report.Drift = append(report.Drift, dto.DriftDto{
+				Name:           database.Name,
+				Email:          database.Email,
+				DirectoryUUID:  database.DirectoryUUID,
+				PreviousStatus: database.Status,
+				Status:         status,
+			})
+		}
+
+		for _, container := range containers {
+			if known[container.DeploymentUUID] {
+				continue
+			}
+
+			log.Println("Orphaned container", container.Name, "on node", location)
+			report.Orphans = append(report.Orphans, dto.OrphanDto{
+				Location:       location,
+				Name:           container.Name,
+				DeploymentUUID: container.DeploymentUUID,
+				Role:           container.Role,
+				State:          container.State,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+func observedStatus(current string, containers map[string]string) string {
+
+	dbState, exists := containers[containerRoleDatabase]
+	if !exists {
+		return models.StatusMissing
+	}
+
+	dbRunning := dbState == containerRunning
+	exporterRunning := containers[containerRoleExporter] == containerRunning
+
+	if current == models.StatusStopped && !dbRunning {
+		return models.StatusStopped
+	}
+
+	if dbRunning && exporterRunning {
+		return models.StatusOnline
+	}
+
+	return models.StatusDegraded
+}
+
+func listContainers(address string) ([]ContainerInfo, error) {
+
+	client, err := rpc.DialHTTP("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	var reply ListContainersResponse
+	err = client.Call("RPCServer.ListContainers", ListContainersPayload{}, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.Status != "OK" {
+		return nil, errors.New("node failed to list containers")
+	}
+
+	return reply.Containers, nil
+}
@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"config-service/compute-tier"
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"errors"
+	"log"
+	"net/http"
+	"net/rpc"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxReplicas = 5
+
+var errReplicaNotDeleted = errors.New("node failed to delete replica")
+
+type CreateReplicaPayload struct {
+	PrimaryUUID uuid.UUID
+	UUID        uuid.UUID
+	Type        string
+	Version     string
+	Limits      ComputeLimits
+	StorageMB   int64
+}
+
+func CreateReplicas(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var replicasDto dto.ReplicasDto
+
+	if err := c.BindJSON(&replicasDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	count := replicasDto.Count
+	if count == 0 {
+		count = 1
+	}
+
+	if count < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Count must be positive"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Previous != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Confirm or roll back the pending upgrade first"})
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	replicas, err := models.DB.ReplicaEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if len(replicas)+count > maxReplicas {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "A database can have at most " + strconv.Itoa(maxReplicas) + " replicas, it has " + strconv.Itoa(len(replicas)),
+		})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	primaryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	tier, _ := compute.GetTier(database.Configuration.ServiceType, database.Configuration.ComputeType)
+	storageMB, _ := storageSizeMB(database.Configuration.MaxStorageSize, database.Configuration.StorageSizeUnit)
+
+	response := []string{}
+
+	for i := 0; i < count; i++ {
+		replica := models.ReplicaEntry{
+			DirectoryUUID: uuid.New().String(),
+			PrimaryUUID:   database.DirectoryUUID,
+			DatabaseID:    database.ID.Hex(),
+			Database:      database.Name,
+			Email:         email,
+			Server:        database.Server,
+			Status:        models.StatusProvisioning,
+			CreatedAt:     time.Now(),
+		}
+
+		err = models.DB.ReplicaEntry.Insert(replica)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		payload := CreateReplicaPayload{
+			PrimaryUUID: primaryUUID,
+			UUID:        uuid.MustParse(replica.DirectoryUUID),
+			Type:        database.Type,
+			Version:     database.Version,
+			Limits:      ComputeLimits(tier),
+			StorageMB:   storageMB,
+		}
+
+		go createReplica(payload, server)
+
+		response = append(response, replica.DirectoryUUID)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"replicas": response,
+	})
+}
+
+func createReplica(payload CreateReplicaPayload, server *models.ServerEntry) {
+
+	directoryUUID := payload.UUID.String()
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.ReplicaEntry.Fail(directoryUUID, "Can't reach node for server "+server.Name)
+		return
+	}
+	defer client.Close()
+
+	var reply CreateDatabaseResponse
+
+	err = client.Call("RPCServer.CreateReplica", payload, &reply)
+	if err != nil {
+		log.Println("Error when calling node rpc")
+		models.DB.ReplicaEntry.Fail(directoryUUID, "Node call failed: "+err.Error())
+		return
+	}
+
+	if reply.Status != "CREATED" {
+		models.DB.ReplicaEntry.Fail(directoryUUID, reply.Error)
+		return
+	}
+
+	err = models.DB.ReplicaEntry.Ready(directoryUUID, strings.Split(reply.NodeIP, ":")[0], reply.NodePort, reply.VolumePath)
+	if err != nil {
+		log.Println("Error: Failed to mark replica", directoryUUID, "online")
+	}
+}
+
+func DeleteReplica(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	replica, err := models.DB.ReplicaEntry.GetOne(c.Param("replica"), email)
+	if err != nil || replica.DatabaseID != database.ID.Hex() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if replica.Status == models.StatusProvisioning {
+		c.JSON(http.StatusConflict, gin.H{"error": "Replica is still being provisioned"})
+		return
+	}
+
+	if replica.Status == models.StatusFailed {
+		err = models.DB.ReplicaEntry.Delete(replica.DirectoryUUID)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(replica.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = models.DB.ReplicaEntry.UpdateStatus(replica.DirectoryUUID, models.StatusDeleting)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.ReplicaEntry.UpdateStatus(replica.DirectoryUUID, replica.Status)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = deleteReplica(client, replica, database.Type)
+	if err != nil {
+		models.DB.ReplicaEntry.UpdateStatus(replica.DirectoryUUID, replica.Status)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete replica"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func deleteReplica(client *rpc.Client, replica *models.ReplicaEntry, dbType string) error {
+
+	directoryUUID, err := uuid.Parse(replica.DirectoryUUID)
+	if err != nil {
+		return err
+	}
+
+	if replica.Status != models.StatusFailed {
+		var reply DeleteDatabaseResponse
+		payload := DeleteDatabasePayload{
+			UUID:       directoryUUID,
+			Type:       dbType,
+			GrafanaUID: replica.GrafanaUID,
+		}
+
+		err = client.Call("RPCServer.DeleteDatabase", payload, &reply)
+		if err != nil || reply.Status != "DELETED" {
+			log.Println("Error destroying replica", replica.DirectoryUUID)
+			return errReplicaNotDeleted
+		}
+	}
+
+	return models.DB.ReplicaEntry.Delete(replica.DirectoryUUID)
+}
+
+func replicaDtos(replicas []*models.ReplicaEntry) []dto.ReplicaDto {
+
+	response := []dto.ReplicaDto{}
+	for _, replica := range replicas {
+		response = append(response, dto.ReplicaDto{
+			UUID:      replica.DirectoryUUID,
+			Status:    replica.Status,
+			NodeIP:    replica.NodeIP,
+			NodePort:  replica.NodePort,
+			CreatedAt: replica.CreatedAt,
+			Error:     replica.Error,
+		})
+	}
+
+	return response
+}
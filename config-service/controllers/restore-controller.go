@@ -0,0 +1,381 @@
+package controllers
+
+import (
+	"config-service/compute-tier"
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"log"
+	"net/http"
+	"net/rpc"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type RecoveryWindowPayload struct {
+	UUID uuid.UUID
+}
+
+type RecoveryWindowResponse struct {
+	Status     string
+	EarliestAt time.Time
+	LatestAt   time.Time
+}
+
+type RestoreBackupPayload struct {
+	SourceUUID   uuid.UUID
+	BackupUUID   uuid.UUID
+	UUID         uuid.UUID
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	User         string
+	Limits       ComputeLimits
+	StorageMB    int64
+	Key          []byte
+	Checksum     string
+	Connectivity string
+}
+
+type RestoreDatabasePayload struct {
+	SourceUUID   uuid.UUID
+	UUID         uuid.UUID
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	TargetTime   time.Time
+	Limits       ComputeLimits
+	StorageMB    int64
+	Connectivity string
+}
+
+func RestoreDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var restoreDto dto.RestoreDto
+
+	if err := c.BindJSON(&restoreDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	if !databaseNameRegex.MatchString(restoreDto.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid database name"})
+		return
+	}
+
+	if restoreDto.TargetTime.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target time is required"})
+		return
+	}
+
+	if restoreDto.TargetTime.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target time can't be in the future"})
+		return
+	}
+
+	source, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if !reconcilableStatuses[source.Status] {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database can't be restored while " + source.Status})
+		return
+	}
+
+	if _, err := models.DB.DatabaseEntry.GetOne(restoreDto.Name, email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database " + restoreDto.Name + " already exists"})
+		return
+	}
+
+	if restoreDto.Environment == "" {
+		restoreDto.Environment = source.Environment
+	}
+
+	generated := restoreDto.Password == ""
+	if generated {
+		restoreDto.Password, err = generatePassword()
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(source.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	sourceUUID, err := uuid.Parse(source.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	var windowReply RecoveryWindowResponse
+	err = client.Call("RPCServer.RecoveryWindow", RecoveryWindowPayload{UUID: sourceUUID}, &windowReply)
+	if err != nil || windowReply.Status == "ERROR" {
+		log.Println("Error when calling node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read the recovery window"})
+		return
+	}
+
+	if windowReply.Status != "OK" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No base backup with archived WAL is available for this database yet"})
+		return
+	}
+
+	if restoreDto.TargetTime.Before(windowReply.EarliestAt) || restoreDto.TargetTime.After(windowReply.LatestAt) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Target time is outside of the recovery window",
+			"recovery_window": dto.RecoveryWindowDto{
+				EarliestAt: windowReply.EarliestAt,
+				LatestAt:   windowReply.LatestAt,
+			},
+		})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(restoreDto.Password), 10)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID := uuid.New()
+
+	database := models.DatabaseEntry{
+		Name:          restoreDto.Name,
+		Password:      string(hash),
+		Server:        source.Server,
+		Environment:   restoreDto.Environment,
+		Configuration: source.Configuration,
+		Connectivity:  source.Connectivity,
+		Type:          source.Type,
+		Version:       source.Version,
+		DirectoryUUID: directoryUUID.String(),
+		GrafanaUID:    "",
+		Email:         email,
+		CreatedAt:     time.Now(),
+		Status:        models.StatusProvisioning,
+		PasswordSetAt: time.Now(),
+		Provisioning: &models.Provisioning{
+			State: models.StatusProvisioning,
+			Steps: []models.ProvisioningStep{
+				{Name: models.StepAccepted, At: time.Now()},
+			},
+		},
+	}
+
+	err = models.DB.DatabaseEntry.Insert(database)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	tier, _ := compute.GetTier(source.Configuration.ServiceType, source.Configuration.ComputeType)
+	storageMB, _ := storageSizeMB(source.Configuration.MaxStorageSize, source.Configuration.StorageSizeUnit)
+
+	payload := RestoreDatabasePayload{
+		SourceUUID:   sourceUUID,
+		UUID:         directoryUUID,
+		Name:         restoreDto.Name,
+		Type:         source.Type,
+		Version:      source.Version,
+		Password:     restoreDto.Password,
+		TargetTime:   restoreDto.TargetTime,
+		Limits:       ComputeLimits(tier),
+		StorageMB:    storageMB,
+		Connectivity: source.Connectivity,
+	}
+
+	go requestProvisioning(server, directoryUUID.String(), "RPCServer.RestoreDatabase", payload)
+
+	response := gin.H{
+		"uuid": directoryUUID.String(),
+	}
+	if generated {
+		response["password"] = restoreDto.Password
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func RestoreBackup(c *gin.Context) {
+	email := c.Param("email")
+
+	var restoreDto dto.RestoreBackupDto
+
+	if err := c.BindJSON(&restoreDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	if !databaseNameRegex.MatchString(restoreDto.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid database name"})
+		return
+	}
+
+	backup, err := models.DB.BackupEntry.GetOne(c.Param("backup"), email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if backup.Status != models.BackupCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Backup is " + backup.Status})
+		return
+	}
+
+	if backup.Type == "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Backup was taken before restores were supported and can only be downloaded"})
+		return
+	}
+
+	if _, err := models.DB.DatabaseEntry.GetOne(restoreDto.Name, email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database " + restoreDto.Name + " already exists"})
+		return
+	}
+
+	if restoreDto.Environment == "" {
+		restoreDto.Environment = backup.Environment
+	}
+
+	generated := restoreDto.Password == ""
+	if generated {
+		restoreDto.Password, err = generatePassword()
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(backup.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	sourceUUID, err := uuid.Parse(backup.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	backupUUID, err := uuid.Parse(backup.BackupUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	key, err := backupKey(backup)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup key unavailable"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(restoreDto.Password), 10)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	directoryUUID := uuid.New()
+
+	database := models.DatabaseEntry{
+		Name:          restoreDto.Name,
+		Password:      string(hash),
+		Server:        backup.Server,
+		Environment:   restoreDto.Environment,
+		Configuration: backup.Configuration,
+		Connectivity:  backup.Connectivity,
+		Type:          backup.Type,
+		Version:       backup.Version,
+		DirectoryUUID: directoryUUID.String(),
+		GrafanaUID:    "",
+		Email:         email,
+		CreatedAt:     time.Now(),
+		Status:        models.StatusProvisioning,
+		PasswordSetAt: time.Now(),
+		Provisioning: &models.Provisioning{
+			State: models.StatusProvisioning,
+			Steps: []models.ProvisioningStep{
+				{Name: models.StepAccepted, At: time.Now()},
+			},
+		},
+	}
+
+	err = models.DB.DatabaseEntry.Insert(database)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	tier, _ := compute.GetTier(backup.Configuration.ServiceType, backup.Configuration.ComputeType)
+	storageMB, _ := storageSizeMB(backup.Configuration.MaxStorageSize, backup.Configuration.StorageSizeUnit)
+
+	payload := RestoreBackupPayload{
+		SourceUUID:   sourceUUID,
+		BackupUUID:   backupUUID,
+		UUID:         directoryUUID,
+		Name:         restoreDto.Name,
+		Type:         backup.Type,
+		Version:      backup.Version,
+		Password:     restoreDto.Password,
+		User:         server.Admin,
+		Limits:       ComputeLimits(tier),
+		StorageMB:    storageMB,
+		Key:          key,
+		Checksum:     backup.Checksum,
+		Connectivity: backup.Connectivity,
+	}
+
+	go requestProvisioning(server, directoryUUID.String(), "RPCServer.RestoreBackup", payload)
+
+	response := gin.H{
+		"uuid": directoryUUID.String(),
+	}
+	if generated {
+		response["password"] = restoreDto.Password
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func requestProvisioning(server *models.ServerEntry, directoryUUID string, method string, payload any) {
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.FailProvisioning(directoryUUID, "Can't reach node for server "+server.Name)
+		return
+	}
+	defer client.Close()
+
+	err = models.DB.DatabaseEntry.AddProvisioningStep(directoryUUID, models.StepNodeRequested)
+	if err != nil {
+		models.DB.DatabaseEntry.FailProvisioning(directoryUUID, "Failed to record provisioning progress")
+		return
+	}
+
+	var reply CreateDatabaseResponse
+	err = client.Call(method, payload, &reply)
+	finishProvisioning(directoryUUID, reply, err)
+}
@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"config-service/compute-tier"
+	"config-service/dto"
+	"config-service/models"
+	"config-service/node-info"
+	"log"
+	"net/http"
+	"net/rpc"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UpgradeDatabasePayload struct {
+	UUID      uuid.UUID
+	NewUUID   uuid.UUID
+	Type      string
+	Version   string
+	Limits    ComputeLimits
+	StorageMB int64
+}
+
+type UpgradeDatabaseResponse struct {
+	Status     string
+	NodePort   string
+	VolumePath string
+}
+
+type FinishUpgradePayload struct {
+	UUID       uuid.UUID
+	NewUUID    uuid.UUID
+	Type       string
+	GrafanaUID string
+}
+
+type FinishUpgradeResponse struct {
+	Status string
+}
+
+type TemplatePayload struct {
+	Type    string
+	Version string
+}
+
+type TemplateResponse struct {
+	Status string
+}
+
+func UpgradeDatabase(c *gin.Context) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	var upgradeDto dto.UpgradeDto
+
+	if err := c.BindJSON(&upgradeDto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the body"})
+		return
+	}
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Previous != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Confirm or roll back the pending upgrade first"})
+		return
+	}
+
+	replicas, err := models.DB.ReplicaEntry.GetAllByDatabase(database.ID.Hex())
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if len(replicas) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delete the replicas of the database first"})
+		return
+	}
+
+	if database.Status != models.StatusOnline {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Database must be " + models.StatusOnline + ", current status is " + database.Status,
+		})
+		return
+	}
+
+	if !isNewerVersion(upgradeDto.Version, database.Version) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target version must be newer than " + database.Version})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	var templateReply TemplateResponse
+	err = client.Call("RPCServer.TemplateExists", TemplatePayload{Type: database.Type, Version: upgradeDto.Version}, &templateReply)
+	if err != nil {
+		log.Println("Error when calling node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to check the target version"})
+		return
+	}
+
+	if templateReply.Status != "OK" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Version " + upgradeDto.Version + " is not available for " + database.Type})
+		return
+	}
+
+	tier, _ := compute.GetTier(database.Configuration.ServiceType, database.Configuration.ComputeType)
+	storageMB, _ := storageSizeMB(database.Configuration.MaxStorageSize, database.Configuration.StorageSizeUnit)
+
+	updated, err := models.DB.DatabaseEntry.UpdateStatusIf(database.DirectoryUUID, models.StatusOnline, models.StatusUpgrading)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database status changed, try again"})
+		return
+	}
+
+	newUUID := uuid.New()
+	go upgradeDatabase(database, server, newUUID, upgradeDto.Version, tier, storageMB)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"uuid": newUUID.String(),
+	})
+}
+
+func upgradeDatabase(database *models.DatabaseEntry, server *models.ServerEntry, newUUID uuid.UUID, version string, tier compute.Tier, storageMB int64) {
+
+	directoryUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		log.Println("Error parsing deployment uuid")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		return
+	}
+
+	var reply UpgradeDatabaseResponse
+	payload := UpgradeDatabasePayload{
+		UUID:      directoryUUID,
+		NewUUID:   newUUID,
+		Type:      database.Type,
+		Version:   version,
+		Limits:    ComputeLimits(tier),
+		StorageMB: storageMB,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		return
+	}
+	defer client.Close()
+
+	err = client.Call("RPCServer.UpgradeDatabase", payload, &reply)
+	if err != nil || reply.Status != "UPGRADED" {
+		log.Println("Error when calling node rpc")
+		models.DB.DatabaseEntry.UpdateStatus(database.DirectoryUUID, database.Status)
+		return
+	}
+
+	deployment := models.Deployment{
+		DirectoryUUID: newUUID.String(),
+		Version:       version,
+		NodePort:      reply.NodePort,
+		VolumePath:    reply.VolumePath,
+		GrafanaUID:    database.GrafanaUID,
+	}
+
+	previous := models.Deployment{
+		DirectoryUUID: database.DirectoryUUID,
+		Version:       database.Version,
+		NodePort:      database.NodePort,
+		VolumePath:    database.VolumePath,
+		GrafanaUID:    database.GrafanaUID,
+	}
+
+	err = models.DB.DatabaseEntry.SwitchDeployment(database.DirectoryUUID, deployment, &previous)
+	if err != nil {
+		log.Println("Error: Failed to switch database entry to upgraded deployment")
+	}
+}
+
+func ConfirmUpgrade(c *gin.Context) {
+	finishUpgrade(c, "RPCServer.ConfirmUpgrade", "CONFIRMED")
+}
+
+func RollbackUpgrade(c *gin.Context) {
+	finishUpgrade(c, "RPCServer.RollbackUpgrade", "ROLLED_BACK")
+}
+
+func finishUpgrade(c *gin.Context, method string, expectedStatus string) {
+	email := c.Param("email")
+	name := c.Param("name")
+
+	database, err := models.DB.DatabaseEntry.GetOne(name, email)
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if database.Previous == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Database has no pending upgrade"})
+		return
+	}
+
+	server, err := models.DB.ServerEntry.GetOne(database.Server)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	previousUUID, err := uuid.Parse(database.Previous.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	currentUUID, err := uuid.Parse(database.DirectoryUUID)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var reply FinishUpgradeResponse
+	payload := FinishUpgradePayload{
+		UUID:       previousUUID,
+		NewUUID:    currentUUID,
+		Type:       database.Type,
+		GrafanaUID: database.Previous.GrafanaUID,
+	}
+
+	client, err := rpc.DialHTTP("tcp", node.LocationServerMp[server.Location])
+	if err != nil {
+		log.Println("Error when dialing node rpc")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Can't reach node"})
+		return
+	}
+	defer client.Close()
+
+	err = client.Call(method, payload, &reply)
+	if err != nil || reply.Status != expectedStatus {
+		log.Println("Error when calling node rpc")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to finish upgrade"})
+		return
+	}
+
+	if expectedStatus == "ROLLED_BACK" {
+		err = models.DB.DatabaseEntry.SwitchDeployment(database.DirectoryUUID, *database.Previous, nil)
+	} else {
+		err = models.DB.DatabaseEntry.ClearPrevious(database.DirectoryUUID)
+	}
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func isNewerVersion(target string, current string) bool {
+	targetParts := strings.Split(target, ".")
+	currentParts := strings.Split(current, ".")
+
+	for i := 0; i < len(targetParts) || i < len(currentParts); i++ {
+		var t, c int
+		var err error
+
+		if i < len(targetParts) {
+			t, err = strconv.Atoi(targetParts[i])
+			if err != nil {
+				return false
+			}
+		}
+
+		if i < len(currentParts) {
+			c, _ = strconv.Atoi(currentParts[i])
+		}
+
+		if t != c {
+			return t > c
+		}
+	}
+
+	return false
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type ServerDto struct {
 	Name     string `json:"name"`
 	Location string `json:"location"`
@@ -39,6 +41,70 @@ type ConfigurationDto struct {
 	StorageSizeUnit string `json:"storage_size_unit"`
 }
 
+type ResizeDto struct {
+	ServiceType string `json:"service_type"`
+	ComputeType string `json:"compute_type"`
+}
+
+type StorageDto struct {
+	MaxStorageSize  string `json:"max_storage_size"`
+	StorageSizeUnit string `json:"storage_size_unit"`
+}
+
+type StorageUsageDto struct {
+	UsedBytes      int64   `json:"used_bytes"`
+	TotalBytes     int64   `json:"total_bytes"`
+	UsedPercentage float64 `json:"used_percentage"`
+	LimitReached   bool    `json:"limit_reached"`
+}
+
+type PasswordDto struct {
+	Password string `json:"password"`
+}
+
+type CloneDto struct {
+	Name        string `json:"name"`
+	Password    string `json:"password"`
+	Server      string `json:"server"`
+	Environment string `json:"environment"`
+}
+
+type RestoreDto struct {
+	Name        string    `json:"name"`
+	Password    string    `json:"password"`
+	Environment string    `json:"environment"`
+	TargetTime  time.Time `json:"target_time"`
+}
+
+type RestoreBackupDto struct {
+	Name        string `json:"name"`
+	Password    string `json:"password"`
+	Environment string `json:"environment"`
+}
+
+type RecoveryWindowDto struct {
+	EarliestAt time.Time `json:"earliest_at"`
+	LatestAt   time.Time `json:"latest_at"`
+}
+
+type UpdateDatabaseDto struct {
+	Name         *string `json:"name"`
+	Environment  *string `json:"environment"`
+	Connectivity *string `json:"connectivity"`
+}
+
+type MigrateDto struct {
+	Server string `json:"server"`
+}
+
+type UpgradeDto struct {
+	Version string `json:"version"`
+}
+
+type ReplicasDto struct {
+	Count int `json:"count"`
+}
+
 type NodeDatabaseDto struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
@@ -56,6 +122,52 @@ type DatabaseOverviewDto struct {
 	Version       string           `json:"version"`
 	NodeIP        string           `json:"node_ip"`
 	NodePort      string           `json:"node_port"`
+	PasswordSetAt time.Time        `json:"password_set_at"`
+	Previous      string           `json:"previous_version,omitempty"`
+	Provisioning  *ProvisioningDto `json:"provisioning,omitempty"`
+	Migration     *MigrationDto    `json:"migration,omitempty"`
+	Replicas      []ReplicaDto     `json:"replicas"`
+}
+
+type ReplicaDto struct {
+	UUID      string    `json:"uuid"`
+	Status    string    `json:"status"`
+	NodeIP    string    `json:"node_ip"`
+	NodePort  string    `json:"node_port"`
+	CreatedAt time.Time `json:"created_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type MigrationDto struct {
+	SourceServer string    `json:"source_server"`
+	TargetServer string    `json:"target_server"`
+	State        string    `json:"state"`
+	Reason       string    `json:"reason,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+}
+
+type ProvisioningDto struct {
+	State  string                `json:"state"`
+	Reason string                `json:"reason,omitempty"`
+	Steps  []ProvisioningStepDto `json:"steps"`
+}
+
+type ProvisioningStepDto struct {
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+}
+
+type ConnectionDto struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	User     string `json:"user"`
+	Database string `json:"database"`
+	SSLMode  string `json:"sslmode"`
+	URI      string `json:"uri"`
+	KeyValue string `json:"key_value"`
+	JDBC     string `json:"jdbc"`
+	Go       string `json:"go"`
 }
 
 type DatabaseGrafanaDto struct {
@@ -68,3 +180,77 @@ type ResourceDto struct {
 	Location string `json:"location"`
 	Server   string `json:"server"`
 }
+
+type ReconcileReportDto struct {
+	CheckedAt time.Time       `json:"checked_at"`
+	Nodes     []NodeReportDto `json:"nodes"`
+	Drift     []DriftDto      `json:"drift"`
+	Orphans   []OrphanDto     `json:"orphans"`
+}
+
+type NodeReportDto struct {
+	Location  string `json:"location"`
+	Reachable bool   `json:"reachable"`
+}
+
+type DriftDto struct {
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	DirectoryUUID  string `json:"directory_uuid"`
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+}
+
+type OrphanDto struct {
+	Location       string `json:"location"`
+	Name           string `json:"name"`
+	DeploymentUUID string `json:"deployment_uuid"`
+	Role           string `json:"role"`
+	State          string `json:"state"`
+}
+
+type BackupDto struct {
+	UUID       string    `json:"uuid"`
+	Database   string    `json:"database"`
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	SizeBytes  int64     `json:"size_bytes"`
+	Checksum   string    `json:"checksum"`
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+	Encrypted  bool      `json:"encrypted"`
+
+	LastVerifiedAt    time.Time `json:"last_verified_at"`
+	VerificationError string    `json:"verification_error,omitempty"`
+}
+
+type BackupPolicyDto struct {
+	Schedule   string `json:"schedule"`
+	KeepDaily  int    `json:"keep_daily"`
+	KeepWeekly int    `json:"keep_weekly"`
+}
+
+type BackupPolicyOverviewDto struct {
+	Schedule   string    `json:"schedule"`
+	KeepDaily  int       `json:"keep_daily"`
+	KeepWeekly int       `json:"keep_weekly"`
+	IsDefault  bool      `json:"is_default"`
+	NextRunAt  time.Time `json:"next_run_at"`
+}
+
+type ImportDto struct {
+	UUID          string    `json:"uuid"`
+	Database      string    `json:"database"`
+	Filename      string    `json:"filename"`
+	Format        string    `json:"format,omitempty"`
+	Status        string    `json:"status"`
+	TotalBytes    int64     `json:"total_bytes"`
+	ReceivedBytes int64     `json:"received_bytes"`
+	Percent       int       `json:"percent"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	Error         string    `json:"error,omitempty"`
+}
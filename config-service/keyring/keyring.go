@@ -0,0 +1,88 @@
+package keyring
+
+import (
+	"config-service/models"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// Tenant backup keys are generated on first use and stored wrapped with the
+// master key, so the database alone is not enough to read any backup.
+
+const keySize = 32
+
+var masterKey cipher.AEAD
+
+func Init(encodedKey string) error {
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return err
+	}
+
+	if len(key) != keySize {
+		return errors.New("backup master key must be 32 bytes, base64 encoded")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	masterKey, err = cipher.NewGCM(block)
+	return err
+}
+
+func TenantKey(email string) ([]byte, error) {
+
+	if masterKey == nil {
+		return nil, errors.New("backup master key is not configured")
+	}
+
+	key := make([]byte, keySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := wrap(key, email)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := models.DB.BackupKeyEntry.GetOrInsert(models.BackupKeyEntry{
+		Email:      email,
+		WrappedKey: wrapped,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unwrap(entry.WrappedKey, email)
+}
+
+func wrap(key []byte, email string) ([]byte, error) {
+
+	nonce := make([]byte, masterKey.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return masterKey.Seal(nonce, nonce, key, []byte(email)), nil
+}
+
+func unwrap(wrapped []byte, email string) ([]byte, error) {
+
+	if len(wrapped) < masterKey.NonceSize() {
+		return nil, errors.New("stored backup key is malformed")
+	}
+
+	nonce := wrapped[:masterKey.NonceSize()]
+	return masterKey.Open(nil, nonce, wrapped[masterKey.NonceSize():], []byte(email))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"config-service/controllers"
+	"config-service/keyring"
 	"config-service/models"
 	"context"
 	"log"
@@ -43,15 +44,62 @@ func main() {
 
 	models.New(dbName, client)
 
+	err = keyring.Init(os.Getenv("BACKUP_MASTER_KEY"))
+	if err != nil {
+		log.Panic("Invalid BACKUP_MASTER_KEY: ", err)
+	}
+
+	controllers.ResumeProvisioning()
+	controllers.ResumeMigrations()
+	models.DB.ImportEntry.FailUnfinished("Interrupted by a config-service restart")
+	models.DB.ReplicaEntry.FailUnfinished("Interrupted by a config-service restart")
+	go controllers.Reconcile()
+	go controllers.ScheduleBackups()
+	go controllers.VerifyBackups()
+
 	router := gin.Default()
 
 	router.POST("/servers", controllers.CreateServer)
 	router.POST("/databases", controllers.CreateDatabase)
 	router.GET("/users/:email/databases/:name", controllers.DbOverviewByName)
+	router.DELETE("/users/:email/databases/:name", controllers.DeleteDatabase)
+	router.PATCH("/users/:email/databases/:name", controllers.UpdateDatabase)
+	router.POST("/users/:email/databases/:name/stop", controllers.StopDatabase)
+	router.POST("/users/:email/databases/:name/start", controllers.StartDatabase)
+	router.POST("/users/:email/databases/:name/restart", controllers.RestartDatabase)
+	router.POST("/users/:email/databases/:name/resize", controllers.ResizeDatabase)
+	router.GET("/users/:email/databases/:name/storage", controllers.StorageUsage)
+	router.PATCH("/users/:email/databases/:name/storage", controllers.GrowStorage)
+	router.PUT("/users/:email/databases/:name/password", controllers.ChangePassword)
+	router.POST("/users/:email/databases/:name/clone", controllers.CloneDatabase)
+	router.POST("/users/:email/databases/:name/restore", controllers.RestoreDatabase)
+	router.POST("/users/:email/databases/:name/upgrade", controllers.UpgradeDatabase)
+	router.POST("/users/:email/databases/:name/migrate", controllers.MigrateDatabase)
+	router.POST("/users/:email/databases/:name/replicas", controllers.CreateReplicas)
+	router.DELETE("/users/:email/databases/:name/replicas/:replica", controllers.DeleteReplica)
+	router.POST("/users/:email/databases/:name/upgrade/confirm", controllers.ConfirmUpgrade)
+	router.POST("/users/:email/databases/:name/upgrade/rollback", controllers.RollbackUpgrade)
 	router.GET("/users/:email/databases", controllers.UserDatabases)
 	router.GET("/users/:email/servers", controllers.UserServers)
+	router.GET("/users/:email/volumes", controllers.UserVolumes)
+	router.DELETE("/users/:email/volumes/:uuid", controllers.PurgeVolume)
 	router.GET("/users/:email/databases/:name/grafana", controllers.DbGrafanaUIDByName)
+	router.GET("/users/:email/databases/:name/connection", controllers.DbConnectionByName)
+	router.POST("/users/:email/databases/:name/backups", controllers.CreateBackup)
+	router.GET("/users/:email/databases/:name/backups", controllers.DatabaseBackups)
+	router.GET("/users/:email/databases/:name/backups/:backup/download", controllers.DownloadBackup)
+	router.POST("/users/:email/backups/:backup/restore", controllers.RestoreBackup)
+	router.POST("/users/:email/databases/:name/import", controllers.ImportDatabase)
+	router.GET("/users/:email/databases/:name/imports", controllers.DatabaseImports)
+	router.GET("/users/:email/databases/:name/imports/:import", controllers.DatabaseImport)
+	router.GET("/users/:email/databases/:name/export", controllers.ExportDatabase)
+	router.GET("/users/:email/databases/:name/backup-policy", controllers.BackupPolicy)
+	router.PUT("/users/:email/databases/:name/backup-policy", controllers.UpdateBackupPolicy)
+	router.DELETE("/users/:email/databases/:name/backup-policy", controllers.ResetBackupPolicy)
 	router.PUT("/databases/:directoryUUID", controllers.UpdateGrafanaUIDByDirectoryUUID)
+	router.DELETE("/users/:email/deployments/:uuid", controllers.CancelDeployment)
+	router.GET("/tiers", controllers.ComputeTiers)
+	router.GET("/reconciliation", controllers.ReconcileReport)
 
 	router.Run()
 }
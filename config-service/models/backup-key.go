@@ -0,0 +1,45 @@
+package models
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BackupKeyEntry struct {
+	Email      string    `bson:"email" json:"email"`
+	WrappedKey []byte    `bson:"wrapped_key" json:"-"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+}
+
+// GetOrInsert returns the tenant's key entry, storing entry first if the
+// tenant has none yet. Concurrent callers all end up with the same key.
+func (b *BackupKeyEntry) GetOrInsert(entry BackupKeyEntry) (*BackupKeyEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup_key")
+
+	filter := bson.M{"email": entry.Email}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"email":       entry.Email,
+			"wrapped_key": entry.WrappedKey,
+			"created_at":  entry.CreatedAt,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stored BackupKeyEntry
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&stored)
+	if err != nil {
+		log.Println("Error getting backup key entry. Error: ", err)
+		return nil, err
+	}
+
+	return &stored, nil
+}
@@ -0,0 +1,274 @@
+package models
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	BackupCreating  string = "CREATING"
+	BackupCompleted string = "COMPLETED"
+	BackupFailed    string = "FAILED"
+
+	BackupKindManual    string = "MANUAL"
+	BackupKindScheduled string = "SCHEDULED"
+)
+
+type BackupEntry struct {
+	BackupUUID    string        `bson:"backup_uuid" json:"backup_uuid"`
+	DatabaseID    string        `bson:"database_id" json:"database_id"`
+	DirectoryUUID string        `bson:"directory_uuid" json:"directory_uuid"`
+	Database      string        `bson:"database" json:"database"`
+	Server        string        `bson:"server" json:"server"`
+	Email         string        `bson:"email" json:"email"`
+	Type          string        `bson:"type" json:"type"`
+	Version       string        `bson:"version" json:"version"`
+	Environment   string        `bson:"environment" json:"environment"`
+	Connectivity  string        `bson:"connectivity" json:"connectivity"`
+	Configuration Configuration `bson:"configuration" json:"configuration"`
+	Kind          string        `bson:"kind" json:"kind"`
+	Status        string        `bson:"status" json:"status"`
+	SizeBytes     int64         `bson:"size_bytes" json:"size_bytes"`
+	Checksum      string        `bson:"checksum" json:"checksum"`
+	CreatedAt     time.Time     `bson:"created_at" json:"created_at"`
+	StartedAt     time.Time     `bson:"started_at" json:"started_at"`
+	FinishedAt    time.Time     `bson:"finished_at" json:"finished_at"`
+	Error         string        `bson:"error,omitempty" json:"error,omitempty"`
+	Encrypted     bool          `bson:"encrypted" json:"encrypted"`
+
+	LastCheckedAt     time.Time `bson:"last_checked_at,omitempty" json:"last_checked_at"`
+	LastVerifiedAt    time.Time `bson:"last_verified_at,omitempty" json:"last_verified_at"`
+	VerificationError string    `bson:"verification_error,omitempty" json:"verification_error,omitempty"`
+}
+
+func (b *BackupEntry) Insert(entry BackupEntry) error {
+
+	collection := client.Database(DBName).Collection("backup")
+
+	_, err := collection.InsertOne(context.TODO(), BackupEntry{
+		BackupUUID:    entry.BackupUUID,
+		DatabaseID:    entry.DatabaseID,
+		DirectoryUUID: entry.DirectoryUUID,
+		Database:      entry.Database,
+		Server:        entry.Server,
+		Email:         entry.Email,
+		Type:          entry.Type,
+		Version:       entry.Version,
+		Environment:   entry.Environment,
+		Connectivity:  entry.Connectivity,
+		Configuration: entry.Configuration,
+		Kind:          entry.Kind,
+		Status:        entry.Status,
+		SizeBytes:     entry.SizeBytes,
+		Checksum:      entry.Checksum,
+		CreatedAt:     entry.CreatedAt,
+		StartedAt:     entry.StartedAt,
+		FinishedAt:    entry.FinishedAt,
+		Error:         entry.Error,
+		Encrypted:     entry.Encrypted,
+	})
+
+	if err != nil {
+		log.Println("Error inserting backup entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (b *BackupEntry) Complete(backupUUID string, sizeBytes int64, checksum string, startedAt time.Time, finishedAt time.Time) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup")
+
+	filter := bson.M{"backup_uuid": backupUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      BackupCompleted,
+			"size_bytes":  sizeBytes,
+			"checksum":    checksum,
+			"started_at":  startedAt,
+			"finished_at": finishedAt,
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error completing backup entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (b *BackupEntry) Fail(backupUUID string, reason string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup")
+
+	filter := bson.M{"backup_uuid": backupUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      BackupFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error failing backup entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (b *BackupEntry) Delete(backupUUID string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup")
+
+	filter := bson.M{"backup_uuid": backupUUID}
+
+	_, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		log.Println("Error deleting backup entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (b *BackupEntry) GetOne(backupUUID string, email string) (*BackupEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup")
+
+	filter := bson.M{"backup_uuid": backupUUID, "email": email}
+
+	var entry BackupEntry
+	err := collection.FindOne(ctx, filter).Decode(&entry)
+	if err != nil {
+		log.Println("Error getting backup entry. Error: ", err)
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (b *BackupEntry) GetAllByDatabase(databaseID string) ([]*BackupEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup")
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "created_at", Value: -1}})
+	filter := bson.M{"database_id": databaseID}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Println("Error getting backup entries. Error: ", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*BackupEntry
+
+	for cursor.Next(ctx) {
+		var entry BackupEntry
+
+		err = cursor.Decode(&entry)
+		if err != nil {
+			log.Println("Error decoding backup entry. Error: ", err)
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// GetVerificationCandidates returns completed backups that were never checked
+// or not since before, least recently checked first.
+func (b *BackupEntry) GetVerificationCandidates(before time.Time, limit int64) ([]*BackupEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup")
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "last_checked_at", Value: 1}, {Key: "created_at", Value: -1}})
+	opts.SetLimit(limit)
+	filter := bson.M{
+		"status": BackupCompleted,
+		"$or": bson.A{
+			bson.M{"last_checked_at": bson.M{"$exists": false}},
+			bson.M{"last_checked_at": bson.M{"$lt": before}},
+		},
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Println("Error getting backup entries to verify. Error: ", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*BackupEntry
+
+	for cursor.Next(ctx) {
+		var entry BackupEntry
+
+		err = cursor.Decode(&entry)
+		if err != nil {
+			log.Println("Error decoding backup entry. Error: ", err)
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+func (b *BackupEntry) RecordVerification(backupUUID string, checkedAt time.Time, reason string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("backup")
+
+	filter := bson.M{"backup_uuid": backupUUID}
+	set := bson.M{
+		"last_checked_at":    checkedAt,
+		"verification_error": reason,
+	}
+	if reason == "" {
+		set["last_verified_at"] = checkedAt
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		log.Println("Error recording backup verification. Error: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	ImportUploading string = "UPLOADING"
+	ImportRestoring string = "RESTORING"
+	ImportCompleted string = "COMPLETED"
+	ImportFailed    string = "FAILED"
+)
+
+type ImportEntry struct {
+	JobUUID       string    `bson:"job_uuid" json:"job_uuid"`
+	DatabaseID    string    `bson:"database_id" json:"database_id"`
+	DirectoryUUID string    `bson:"directory_uuid" json:"directory_uuid"`
+	Database      string    `bson:"database" json:"database"`
+	Email         string    `bson:"email" json:"email"`
+	Filename      string    `bson:"filename" json:"filename"`
+	Format        string    `bson:"format,omitempty" json:"format,omitempty"`
+	Status        string    `bson:"status" json:"status"`
+	TotalBytes    int64     `bson:"total_bytes" json:"total_bytes"`
+	ReceivedBytes int64     `bson:"received_bytes" json:"received_bytes"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+	FinishedAt    time.Time `bson:"finished_at,omitempty" json:"finished_at"`
+	Error         string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+func (i *ImportEntry) Insert(entry ImportEntry) error {
+
+	collection := client.Database(DBName).Collection("import")
+
+	_, err := collection.InsertOne(context.TODO(), ImportEntry{
+		JobUUID:       entry.JobUUID,
+		DatabaseID:    entry.DatabaseID,
+		DirectoryUUID: entry.DirectoryUUID,
+		Database:      entry.Database,
+		Email:         entry.Email,
+		Filename:      entry.Filename,
+		Status:        entry.Status,
+		TotalBytes:    entry.TotalBytes,
+		CreatedAt:     entry.CreatedAt,
+		UpdatedAt:     entry.UpdatedAt,
+	})
+
+	if err != nil {
+		log.Println("Error inserting import entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (i *ImportEntry) update(jobUUID string, set bson.M) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("import")
+
+	set["updated_at"] = time.Now()
+
+	_, err := collection.UpdateOne(ctx, bson.M{"job_uuid": jobUUID}, bson.M{"$set": set})
+	if err != nil {
+		log.Println("Error updating import entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (i *ImportEntry) UpdateProgress(jobUUID string, receivedBytes int64) error {
+	return i.update(jobUUID, bson.M{"received_bytes": receivedBytes})
+}
+
+func (i *ImportEntry) StartRestore(jobUUID string, receivedBytes int64) error {
+	return i.update(jobUUID, bson.M{
+		"status":         ImportRestoring,
+		"received_bytes": receivedBytes,
+	})
+}
+
+func (i *ImportEntry) Complete(jobUUID string, format string) error {
+	return i.update(jobUUID, bson.M{
+		"status":      ImportCompleted,
+		"format":      format,
+		"finished_at": time.Now(),
+	})
+}
+
+func (i *ImportEntry) Fail(jobUUID string, reason string) error {
+	return i.update(jobUUID, bson.M{
+		"status":      ImportFailed,
+		"error":       reason,
+		"finished_at": time.Now(),
+	})
+}
+
+// FailUnfinished marks imports left running by a previous process as failed,
+// their uploads and restore calls died with it.
+func (i *ImportEntry) FailUnfinished(reason string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("import")
+
+	filter := bson.M{"status": bson.M{"$in": bson.A{ImportUploading, ImportRestoring}}}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      ImportFailed,
+			"error":       reason,
+			"updated_at":  time.Now(),
+			"finished_at": time.Now(),
+		},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		log.Println("Error failing unfinished import entries. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (i *ImportEntry) GetOne(jobUUID string, email string) (*ImportEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("import")
+
+	filter := bson.M{"job_uuid": jobUUID, "email": email}
+
+	var entry ImportEntry
+	err := collection.FindOne(ctx, filter).Decode(&entry)
+	if err != nil {
+		log.Println("Error getting import entry. Error: ", err)
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (i *ImportEntry) GetAllByDatabase(databaseID string) ([]*ImportEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("import")
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "created_at", Value: -1}})
+	filter := bson.M{"database_id": databaseID}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Println("Error getting import entries. Error: ", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*ImportEntry
+
+	for cursor.Next(ctx) {
+		var entry ImportEntry
+
+		err = cursor.Decode(&entry)
+		if err != nil {
+			log.Println("Error decoding import entry. Error: ", err)
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -19,32 +20,111 @@ func New(DBNameP string, clientP *mongo.Client) {
 	client = clientP
 
 	DB = Models{
-		DatabaseEntry: DatabaseEntry{},
-		ServerEntry:   ServerEntry{},
+		DatabaseEntry:  DatabaseEntry{},
+		ServerEntry:    ServerEntry{},
+		VolumeEntry:    VolumeEntry{},
+		BackupEntry:    BackupEntry{},
+		BackupKeyEntry: BackupKeyEntry{},
+		ImportEntry:    ImportEntry{},
+		ReplicaEntry:   ReplicaEntry{},
 	}
 }
 
+const (
+	StatusOnline     string = "ONLINE"
+	StatusDeleting   string = "DELETING"
+	StatusStopping   string = "STOPPING"
+	StatusStopped    string = "STOPPED"
+	StatusStarting   string = "STARTING"
+	StatusRestarting string = "RESTARTING"
+	StatusResizing   string = "RESIZING"
+	StatusUpgrading  string = "UPGRADING"
+	StatusMigrating  string = "MIGRATING"
+
+	StatusProvisioning string = "PROVISIONING"
+	StatusReady        string = "READY"
+	StatusFailed       string = "FAILED"
+	StatusCancelled    string = "CANCELLED"
+	StatusDegraded     string = "DEGRADED"
+	StatusMissing      string = "MISSING"
+
+	StepAccepted      string = "ACCEPTED"
+	StepNodeRequested string = "NODE_REQUESTED"
+
+	MigrationInProgress string = "IN_PROGRESS"
+	MigrationCompleted  string = "COMPLETED"
+	MigrationFailed     string = "FAILED"
+)
+
 type Models struct {
-	DatabaseEntry DatabaseEntry
-	ServerEntry   ServerEntry
+	DatabaseEntry  DatabaseEntry
+	ServerEntry    ServerEntry
+	VolumeEntry    VolumeEntry
+	BackupEntry    BackupEntry
+	BackupKeyEntry BackupKeyEntry
+	ImportEntry    ImportEntry
+	ReplicaEntry   ReplicaEntry
 }
 
 type DatabaseEntry struct {
-	Name          string        `bson:"name" json:"name"`
-	Password      string        `bson:"password" json:"password"`
-	Server        string        `bson:"server" json:"server"`
-	Environment   string        `bson:"environment" json:"environment"`
-	Configuration Configuration `bson:"configuration" json:"configuration"`
-	Connectivity  string        `bson:"connectivity" json:"connectivity"`
-	Type          string        `bson:"type" json:"type"`
-	Version       string        `bson:"version" json:"version"`
-	NodeIP        string        `bson:"node_ip" json:"node_ip"`
-	NodePort      string        `bson:"node_port" json:"node_port"`
-	DirectoryUUID string        `bson:"directory_uuid" json:"directory_uuid"`
-	GrafanaUID    string        `bson:"grafana_uid" json:"grafana_uid"`
-	Email         string        `bson:"email" json:"email"`
-	CreatedAt     time.Time     `bson:"created_at" json:"created_at"`
-	Status        string        `bson:"status" json:"status"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name          string             `bson:"name" json:"name"`
+	Password      string             `bson:"password" json:"password"`
+	Server        string             `bson:"server" json:"server"`
+	Environment   string             `bson:"environment" json:"environment"`
+	Configuration Configuration      `bson:"configuration" json:"configuration"`
+	Connectivity  string             `bson:"connectivity" json:"connectivity"`
+	Type          string             `bson:"type" json:"type"`
+	Version       string             `bson:"version" json:"version"`
+	NodeIP        string             `bson:"node_ip" json:"node_ip"`
+	NodePort      string             `bson:"node_port" json:"node_port"`
+	DirectoryUUID string             `bson:"directory_uuid" json:"directory_uuid"`
+	VolumePath    string             `bson:"volume_path" json:"volume_path"`
+	GrafanaUID    string             `bson:"grafana_uid" json:"grafana_uid"`
+	Email         string             `bson:"email" json:"email"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	Status        string             `bson:"status" json:"status"`
+	PasswordSetAt time.Time          `bson:"password_set_at" json:"password_set_at"`
+	Previous      *Deployment        `bson:"previous,omitempty" json:"previous,omitempty"`
+	Provisioning  *Provisioning      `bson:"provisioning,omitempty" json:"provisioning,omitempty"`
+	BackupPolicy  *BackupPolicy      `bson:"backup_policy,omitempty" json:"backup_policy,omitempty"`
+	NextBackupAt  time.Time          `bson:"next_backup_at" json:"next_backup_at"`
+	Migration     *Migration         `bson:"migration,omitempty" json:"migration,omitempty"`
+}
+
+type Migration struct {
+	DirectoryUUID string    `bson:"directory_uuid" json:"directory_uuid"`
+	SourceServer  string    `bson:"source_server" json:"source_server"`
+	TargetServer  string    `bson:"target_server" json:"target_server"`
+	State         string    `bson:"state" json:"state"`
+	Reason        string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	StartedAt     time.Time `bson:"started_at" json:"started_at"`
+	FinishedAt    time.Time `bson:"finished_at,omitempty" json:"finished_at"`
+}
+
+type BackupPolicy struct {
+	Schedule   string `bson:"schedule" json:"schedule"`
+	KeepDaily  int    `bson:"keep_daily" json:"keep_daily"`
+	KeepWeekly int    `bson:"keep_weekly" json:"keep_weekly"`
+}
+
+type Provisioning struct {
+	State  string             `bson:"state" json:"state"`
+	Reason string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	Steps  []ProvisioningStep `bson:"steps" json:"steps"`
+}
+
+type ProvisioningStep struct {
+	Name string    `bson:"name" json:"name"`
+	At   time.Time `bson:"at" json:"at"`
+}
+
+type Deployment struct {
+	DirectoryUUID string `bson:"directory_uuid" json:"directory_uuid"`
+	Version       string `bson:"version" json:"version"`
+	NodePort      string `bson:"node_port" json:"node_port"`
+	VolumePath    string `bson:"volume_path" json:"volume_path"`
+	GrafanaUID    string `bson:"grafana_uid" json:"grafana_uid"`
 }
 
 type Configuration struct {
@@ -64,6 +144,16 @@ type ServerEntry struct {
 	Status    string    `bson:"status" json:"status"`
 }
 
+type VolumeEntry struct {
+	DirectoryUUID string    `bson:"directory_uuid" json:"directory_uuid"`
+	Database      string    `bson:"database" json:"database"`
+	Server        string    `bson:"server" json:"server"`
+	NodeIP        string    `bson:"node_ip" json:"node_ip"`
+	Path          string    `bson:"path" json:"path"`
+	Email         string    `bson:"email" json:"email"`
+	RetainedAt    time.Time `bson:"retained_at" json:"retained_at"`
+}
+
 func (s *ServerEntry) Insert(entry ServerEntry) error {
 
 	collection := client.Database(DBName).Collection("server")
@@ -109,6 +199,482 @@ func (d *DatabaseEntry) UpdateGrafanaUID(directoryUUID string, grafanaUID string
 	return nil
 }
 
+func (d *DatabaseEntry) UpdateStatus(directoryUUID string, status string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"status": status,
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error updating database status. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) UpdateStatusIf(directoryUUID string, current string, status string) (bool, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "status": current}
+	update := bson.M{
+		"$set": bson.M{
+			"status": status,
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error updating database status. Error: ", err)
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (d *DatabaseEntry) UpdateMetadata(directoryUUID string, name string, environment string, connectivity string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":           name,
+			"environment":    environment,
+			"connectivity":   connectivity,
+			"next_backup_at": time.Time{},
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error updating database metadata. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) UpdateBackupPolicy(directoryUUID string, policy *BackupPolicy) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"backup_policy":  policy,
+			"next_backup_at": time.Time{},
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error updating database backup policy. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) UpdateNextBackupAt(directoryUUID string, next time.Time) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"next_backup_at": next,
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error updating next backup time. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) UpdateConfiguration(directoryUUID string, configuration Configuration) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"configuration": configuration,
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error updating database configuration. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) UpdatePassword(directoryUUID string, password string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"password":        password,
+			"password_set_at": time.Now(),
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error updating database password. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) SwitchDeployment(directoryUUID string, deployment Deployment, previous *Deployment) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$set": bson.M{
+			"directory_uuid": deployment.DirectoryUUID,
+			"version":        deployment.Version,
+			"node_port":      deployment.NodePort,
+			"volume_path":    deployment.VolumePath,
+			"grafana_uid":    deployment.GrafanaUID,
+			"status":         StatusOnline,
+		},
+	}
+
+	if previous != nil {
+		update["$set"].(bson.M)["previous"] = previous
+	} else {
+		update["$unset"] = bson.M{"previous": ""}
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error switching database deployment. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) ClearPrevious(directoryUUID string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$unset": bson.M{
+			"previous": "",
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error clearing previous deployment. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) Delete(directoryUUID string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+
+	_, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		log.Println("Error deleting database entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) AddProvisioningStep(directoryUUID string, step string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+	update := bson.M{
+		"$push": bson.M{
+			"provisioning.steps": ProvisioningStep{Name: step, At: time.Now()},
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error adding provisioning step. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) FinishProvisioning(directoryUUID string, nodeIP string, nodePort string, volumePath string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "provisioning.state": StatusProvisioning}
+	update := bson.M{
+		"$set": bson.M{
+			"node_ip":            nodeIP,
+			"node_port":          nodePort,
+			"volume_path":        volumePath,
+			"status":             StatusOnline,
+			"provisioning.state": StatusReady,
+		},
+		"$push": bson.M{
+			"provisioning.steps": ProvisioningStep{Name: StatusReady, At: time.Now()},
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error finishing provisioning. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) FailProvisioning(directoryUUID string, reason string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "provisioning.state": StatusProvisioning}
+	update := bson.M{
+		"$set": bson.M{
+			"status":              StatusFailed,
+			"provisioning.state":  StatusFailed,
+			"provisioning.reason": reason,
+		},
+		"$push": bson.M{
+			"provisioning.steps": ProvisioningStep{Name: StatusFailed, At: time.Now()},
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error failing provisioning. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) CancelProvisioning(directoryUUID string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "provisioning.state": StatusProvisioning}
+	update := bson.M{
+		"$set": bson.M{
+			"status":             StatusCancelled,
+			"provisioning.state": StatusCancelled,
+		},
+		"$push": bson.M{
+			"provisioning.steps": ProvisioningStep{Name: StatusCancelled, At: time.Now()},
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error cancelling provisioning. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// StartMigration moves an online database into MIGRATING, reporting false if
+// it was not online anymore.
+func (d *DatabaseEntry) StartMigration(directoryUUID string, migration Migration) (bool, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "status": StatusOnline}
+	update := bson.M{
+		"$set": bson.M{
+			"status":    StatusMigrating,
+			"migration": migration,
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error starting migration. Error: ", err)
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// FinishMigration points the database at its deployment on the target node.
+func (d *DatabaseEntry) FinishMigration(directoryUUID string, deployment Deployment, server string, nodeIP string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "status": StatusMigrating}
+	update := bson.M{
+		"$set": bson.M{
+			"directory_uuid":        deployment.DirectoryUUID,
+			"server":                server,
+			"node_ip":               nodeIP,
+			"node_port":             deployment.NodePort,
+			"volume_path":           deployment.VolumePath,
+			"grafana_uid":           deployment.GrafanaUID,
+			"status":                StatusOnline,
+			"migration.state":       MigrationCompleted,
+			"migration.finished_at": time.Now(),
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error finishing migration. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) FailMigration(directoryUUID string, reason string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "status": StatusMigrating}
+	update := bson.M{
+		"$set": bson.M{
+			"status":                StatusOnline,
+			"migration.state":       MigrationFailed,
+			"migration.reason":      reason,
+			"migration.finished_at": time.Now(),
+		},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Println("Error failing migration. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (d *DatabaseEntry) GetAllMigrating() ([]*DatabaseEntry, error) {
+	return findDatabaseEntries(bson.M{"status": StatusMigrating})
+}
+
+func (d *DatabaseEntry) GetAllProvisioning() ([]*DatabaseEntry, error) {
+	return findDatabaseEntries(bson.M{"provisioning.state": StatusProvisioning})
+}
+
+func (d *DatabaseEntry) GetAllEntries() ([]*DatabaseEntry, error) {
+	return findDatabaseEntries(bson.M{})
+}
+
+func findDatabaseEntries(filter bson.M) ([]*DatabaseEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		log.Println("Error getting database entries. Error: ", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*DatabaseEntry
+
+	for cursor.Next(ctx) {
+		var entry DatabaseEntry
+
+		err = cursor.Decode(&entry)
+		if err != nil {
+			log.Println("Error decoding database entry. Error: ", err)
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
 func (d *DatabaseEntry) Insert(entry DatabaseEntry) error {
 
 	collection := client.Database(DBName).Collection("database")
@@ -125,10 +691,16 @@ func (d *DatabaseEntry) Insert(entry DatabaseEntry) error {
 		NodeIP:        entry.NodeIP,
 		NodePort:      entry.NodePort,
 		DirectoryUUID: entry.DirectoryUUID,
+		VolumePath:    entry.VolumePath,
 		GrafanaUID:    entry.GrafanaUID,
 		Email:         entry.Email,
 		CreatedAt:     entry.CreatedAt,
 		Status:        entry.Status,
+		PasswordSetAt: entry.PasswordSetAt,
+		Previous:      entry.Previous,
+		Provisioning:  entry.Provisioning,
+		BackupPolicy:  entry.BackupPolicy,
+		NextBackupAt:  entry.NextBackupAt,
 	})
 
 	if err != nil {
@@ -178,6 +750,25 @@ func (d *DatabaseEntry) GetOne(name string, email string) (*DatabaseEntry, error
 	return &entry, nil
 }
 
+func (d *DatabaseEntry) GetByDirectoryUUID(directoryUUID string, email string) (*DatabaseEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("database")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "email": email}
+
+	var entry DatabaseEntry
+	err := collection.FindOne(ctx, filter).Decode(&entry)
+	if err != nil {
+		log.Println("Error getting database entry. Error: ", err)
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
 func (d *DatabaseEntry) GetAllByEmail(email string) ([]*DatabaseEntry, error) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -247,3 +838,128 @@ func (s *ServerEntry) GetAll(email string) ([]*ServerEntry, error) {
 
 	return entries, nil
 }
+
+func (v *VolumeEntry) Insert(entry VolumeEntry) error {
+
+	collection := client.Database(DBName).Collection("volume")
+
+	_, err := collection.InsertOne(context.TODO(), VolumeEntry{
+		DirectoryUUID: entry.DirectoryUUID,
+		Database:      entry.Database,
+		Server:        entry.Server,
+		NodeIP:        entry.NodeIP,
+		Path:          entry.Path,
+		Email:         entry.Email,
+		RetainedAt:    entry.RetainedAt,
+	})
+
+	if err != nil {
+		log.Println("Error inserting volume entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (v *VolumeEntry) GetOne(directoryUUID string, email string) (*VolumeEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("volume")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "email": email}
+
+	var entry VolumeEntry
+	err := collection.FindOne(ctx, filter).Decode(&entry)
+	if err != nil {
+		log.Println("Error getting volume entry. Error: ", err)
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (v *VolumeEntry) GetAllByEmail(email string) ([]*VolumeEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("volume")
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "retained_at", Value: -1}})
+	filter := bson.M{"email": email}
+
+	cursor, err := collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		log.Println("Error getting volume entries. Error: ", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*VolumeEntry
+
+	for cursor.Next(ctx) {
+		var entry VolumeEntry
+
+		err = cursor.Decode(&entry)
+		if err != nil {
+			log.Println("Error decoding volume entry. Error: ", err)
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+func (v *VolumeEntry) Delete(directoryUUID string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("volume")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+
+	_, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		log.Println("Error deleting volume entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *ServerEntry) GetAllEntries() ([]*ServerEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("server")
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		log.Println("Error getting server entries. Error: ", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*ServerEntry
+
+	for cursor.Next(ctx) {
+		var entry ServerEntry
+
+		err = cursor.Decode(&entry)
+		if err != nil {
+			log.Println("Error decoding server entry. Error: ", err)
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
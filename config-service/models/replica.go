@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReplicaEntry struct {
+	DirectoryUUID string    `bson:"directory_uuid" json:"directory_uuid"`
+	PrimaryUUID   string    `bson:"primary_uuid" json:"primary_uuid"`
+	DatabaseID    string    `bson:"database_id" json:"database_id"`
+	Database      string    `bson:"database" json:"database"`
+	Email         string    `bson:"email" json:"email"`
+	Server        string    `bson:"server" json:"server"`
+	NodeIP        string    `bson:"node_ip" json:"node_ip"`
+	NodePort      string    `bson:"node_port" json:"node_port"`
+	VolumePath    string    `bson:"volume_path" json:"volume_path"`
+	GrafanaUID    string    `bson:"grafana_uid" json:"grafana_uid"`
+	Status        string    `bson:"status" json:"status"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	Error         string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+func (r *ReplicaEntry) Insert(entry ReplicaEntry) error {
+
+	collection := client.Database(DBName).Collection("replica")
+
+	_, err := collection.InsertOne(context.TODO(), ReplicaEntry{
+		DirectoryUUID: entry.DirectoryUUID,
+		PrimaryUUID:   entry.PrimaryUUID,
+		DatabaseID:    entry.DatabaseID,
+		Database:      entry.Database,
+		Email:         entry.Email,
+		Server:        entry.Server,
+		Status:        entry.Status,
+		CreatedAt:     entry.CreatedAt,
+	})
+
+	if err != nil {
+		log.Println("Error inserting replica entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReplicaEntry) update(directoryUUID string, set bson.M) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("replica")
+
+	_, err := collection.UpdateOne(ctx, bson.M{"directory_uuid": directoryUUID}, bson.M{"$set": set})
+	if err != nil {
+		log.Println("Error updating replica entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReplicaEntry) Ready(directoryUUID string, nodeIP string, nodePort string, volumePath string) error {
+	return r.update(directoryUUID, bson.M{
+		"status":      StatusOnline,
+		"node_ip":     nodeIP,
+		"node_port":   nodePort,
+		"volume_path": volumePath,
+	})
+}
+
+func (r *ReplicaEntry) Fail(directoryUUID string, reason string) error {
+	return r.update(directoryUUID, bson.M{
+		"status": StatusFailed,
+		"error":  reason,
+	})
+}
+
+func (r *ReplicaEntry) UpdateStatus(directoryUUID string, status string) error {
+	return r.update(directoryUUID, bson.M{"status": status})
+}
+
+func (r *ReplicaEntry) UpdateGrafanaUID(directoryUUID string, grafanaUID string) error {
+	return r.update(directoryUUID, bson.M{"grafana_uid": grafanaUID})
+}
+
+// FailUnfinished marks replicas left provisioning by a previous process as
+// failed, the node call waiting for them died with it.
+func (r *ReplicaEntry) FailUnfinished(reason string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("replica")
+
+	filter := bson.M{"status": StatusProvisioning}
+	update := bson.M{
+		"$set": bson.M{
+			"status": StatusFailed,
+			"error":  reason,
+		},
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		log.Println("Error failing unfinished replica entries. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReplicaEntry) Delete(directoryUUID string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("replica")
+
+	filter := bson.M{"directory_uuid": directoryUUID}
+
+	_, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		log.Println("Error deleting replica entry. Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReplicaEntry) GetOne(directoryUUID string, email string) (*ReplicaEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("replica")
+
+	filter := bson.M{"directory_uuid": directoryUUID, "email": email}
+
+	var entry ReplicaEntry
+	err := collection.FindOne(ctx, filter).Decode(&entry)
+	if err != nil {
+		log.Println("Error getting replica entry. Error: ", err)
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *ReplicaEntry) GetAllByDatabase(databaseID string) ([]*ReplicaEntry, error) {
+	return r.find(bson.M{"database_id": databaseID})
+}
+
+func (r *ReplicaEntry) GetAllEntries() ([]*ReplicaEntry, error) {
+	return r.find(bson.M{})
+}
+
+func (r *ReplicaEntry) find(filter bson.M) ([]*ReplicaEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	collection := client.Database(DBName).Collection("replica")
+
+	opts := options.Find()
+	opts.SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Println("Error getting replica entries. Error: ", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*ReplicaEntry
+
+	for cursor.Next(ctx) {
+		var entry ReplicaEntry
+
+		err = cursor.Decode(&entry)
+		if err != nil {
+			log.Println("Error decoding replica entry. Error: ", err)
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
@@ -87,8 +87,15 @@ func (consumer *Consumer) Listen() error {
 		for message := range messages {
 			var payload Job
 			_ = json.Unmarshal(message.Body, &payload)
-			go consumer.updatePrometheusTargets(payload.NodeIP, payload.NodePort)
-			go consumer.createNewDashboard(payload.DashboardName, payload.DbType, fmt.Sprintf("%s:%s", payload.NodeIP, payload.NodePort), payload.Datname)
+
+			switch payload.Action {
+			case DeleteAction:
+				go consumer.removePrometheusTarget(payload.NodeIP, payload.NodePort)
+				go consumer.deleteDashboard(payload.GrafanaUID)
+			default:
+				go consumer.updatePrometheusTargets(payload.NodeIP, payload.NodePort)
+				go consumer.createNewDashboard(payload.DashboardName, payload.DbType, fmt.Sprintf("%s:%s", payload.NodeIP, payload.NodePort), payload.Datname)
+			}
 		}
 	}()
 
@@ -115,7 +122,14 @@ func (consumer *Consumer) updatePrometheusTargets(nodeIP, nodePort string) {
 		return
 	}
 
-	targets[0].Targets = append(targets[0].Targets, fmt.Sprintf("%s:%s", nodeIP, nodePort))
+	target := fmt.Sprintf("%s:%s", nodeIP, nodePort)
+	for _, existing := range targets[0].Targets {
+		if existing == target {
+			return
+		}
+	}
+
+	targets[0].Targets = append(targets[0].Targets, target)
 
 	newTargets, _ := json.MarshalIndent(targets, "", " ")
 	err = os.WriteFile(consumer.TargetsFilePath, newTargets, 0644)
@@ -127,6 +141,71 @@ func (consumer *Consumer) updatePrometheusTargets(nodeIP, nodePort string) {
 	log.Println("Successfully wrote new target in prometheus targets file")
 }
 
+func (consumer *Consumer) removePrometheusTarget(nodeIP, nodePort string) {
+	consumer.TargetsMtx.Lock()
+	defer consumer.TargetsMtx.Unlock()
+
+	file, err := os.Open(consumer.TargetsFilePath)
+	if err != nil {
+		log.Println("Can't open prometheus targets file")
+		return
+	}
+	defer file.Close()
+
+	var targets []Targets
+	decoder := json.NewDecoder(file)
+	if err = decoder.Decode(&targets); err != nil {
+		log.Println("Error while decoding prometheus targets file")
+		return
+	}
+
+	target := fmt.Sprintf("%s:%s", nodeIP, nodePort)
+	remainingTargets := []string{}
+
+	for _, t := range targets[0].Targets {
+		if t != target {
+			remainingTargets = append(remainingTargets, t)
+		}
+	}
+	targets[0].Targets = remainingTargets
+
+	newTargets, _ := json.MarshalIndent(targets, "", " ")
+	err = os.WriteFile(consumer.TargetsFilePath, newTargets, 0644)
+	if err != nil {
+		log.Println("Error removing target from prometheus targets file")
+		return
+	}
+
+	log.Println("Successfully removed target from prometheus targets file")
+}
+
+func (consumer *Consumer) deleteDashboard(grafanaUID string) {
+	if grafanaUID == "" {
+		log.Println("No Grafana dashboard to delete")
+		return
+	}
+
+	url := "http://grafana:3000/api/dashboards/uid/" + grafanaUID
+	request, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		log.Println("Error creating request to Grafana API")
+		return
+	}
+	request.SetBasicAuth("admin", "admin")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		log.Println("Error when deleting dashboard through Grafana API")
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		log.Println("Grafana API returned status", response.StatusCode, "when deleting dashboard")
+	}
+}
+
 func (consumer *Consumer) createNewDashboard(dashboardName string, dbType string, instance string, datname string) {
 	tempFilePath := dashboardName + ".json"
 
@@ -178,6 +257,10 @@ func (consumer *Consumer) createNewDashboard(dashboardName string, dbType string
 		}
 
 		uid, _ := responseData["uid"].(string)
+		if uid == "" {
+			log.Println("Grafana did not create dashboard", dashboardName)
+			return
+		}
 		consumer.sendUIDToConfigService(uid, dashboardName)
 	}
 }
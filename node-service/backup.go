@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"node-service/backupcrypt"
+	"node-service/backupstore"
+	"node-service/rabbit"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	backupsDir           = "backups"
+	backupFileSuffix     = ".dump"
+	backupCompression    = "6"
+	backupDownloadPath   = "/backups/"
+	backupKeyHeader      = "X-Backup-Key"
+	backupChecksumHeader = "X-Backup-Checksum"
+)
+
+var errChecksumMismatch = errors.New("backup checksum does not match")
+
+type CreateBackupPayload struct {
+	UUID       uuid.UUID
+	BackupUUID uuid.UUID
+	Key        []byte
+}
+
+type CreateBackupResponse struct {
+	Status     string
+	SizeBytes  int64
+	Checksum   string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      string
+}
+
+type RestoreBackupPayload struct {
+	SourceUUID   uuid.UUID
+	BackupUUID   uuid.UUID
+	UUID         uuid.UUID
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	User         string
+	Limits       ComputeLimits
+	StorageMB    int64
+	Key          []byte
+	Checksum     string
+	Connectivity string
+}
+
+type DeleteBackupPayload struct {
+	UUID       uuid.UUID
+	BackupUUID uuid.UUID
+}
+
+type DeleteBackupResponse struct {
+	Status string
+}
+
+func (r *RPCServer) CreateBackup(payload CreateBackupPayload, reply *CreateBackupResponse) error {
+
+	(*reply).StartedAt = time.Now()
+
+	vars, err := readTerraformVars(payload.UUID.String())
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+	defer dockerClient.Close()
+
+	size, checksum, err := createBackup(context.Background(), dockerClient, vars, payload.UUID.String(), payload.BackupUUID.String(), payload.Key)
+	if err != nil {
+		log.Println("Error creating backup:", err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	(*reply).Status = "CREATED"
+	(*reply).SizeBytes = size
+	(*reply).Checksum = checksum
+	(*reply).FinishedAt = time.Now()
+	return nil
+}
+
+func (r *RPCServer) RestoreBackup(payload RestoreBackupPayload, reply *CreateDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	ctx, done := r.cancellableDeployment(directoryUUID)
+	defer done()
+
+	tracker := r.trackDeployment(directoryUUID)
+
+	err := os.MkdirAll(directoryUUID, 0750)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(directoryUUID, restoreMarkerFile), []byte(payload.BackupUUID.String()), 0600)
+	}
+	if err != nil {
+		log.Println("Error preparing restore of deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	dbPort, exporterPort, err := r.createDatabase(ctx, payload.Name, payload.Password, payload.User, payload.Type, payload.Version, directoryUUID, payload.Limits, payload.StorageMB, bindAddress(payload.Connectivity))
+	if err == nil {
+		err = loadBackup(ctx, directoryUUID, payload.SourceUUID.String(), payload.BackupUUID.String(), payload.Key, payload.Checksum)
+	}
+
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
+
+	if err != nil {
+		r.discardDeployment(directoryUUID)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", directoryUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		log.Println("Error restoring backup:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	os.Remove(filepath.Join(directoryUUID, restoreMarkerFile))
+	tracker.succeed()
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err == nil {
+		publishMonitoringJob(rabbit.CreateAction, directoryUUID, payload.Type, vars, "")
+	}
+
+	(*reply).Status = "CREATED"
+	(*reply).NodeIP = app.MyIP
+	(*reply).NodePort = dbPort
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(directoryUUID))
+	return nil
+}
+
+func loadBackup(ctx context.Context, directoryUUID string, sourceUUID string, backupUUID string, key []byte, checksum string) error {
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return newDeploymentError(StepPrepare, CategoryInternal, err)
+	}
+
+	backupFile, _, err := BackupStore.Open(ctx, backupKey(sourceUUID, backupUUID))
+	if err != nil {
+		return newDeploymentError(StepDataTransfer, CategoryStorage, err)
+	}
+	defer backupFile.Close()
+
+	dump, err := openArchive(backupFile, key, checksum)
+	if err != nil {
+		return newDeploymentError(StepDataTransfer, CategoryStorage, err)
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return newDeploymentError(StepDataTransfer, CategoryDocker, err)
+	}
+	defer dockerClient.Close()
+
+	err = restoreDatabase(ctx, dockerClient, vars, dump)
+	if err != nil {
+		return newDeploymentError(StepDataTransfer, CategoryDatabase, err)
+	}
+
+	return nil
+}
+
+func (r *RPCServer) DeleteBackup(payload DeleteBackupPayload, reply *DeleteBackupResponse) error {
+
+	err := BackupStore.Delete(context.Background(), backupKey(payload.UUID.String(), payload.BackupUUID.String()))
+	if err != nil && !errors.Is(err, backupstore.ErrNotFound) {
+		log.Println("Error deleting backup:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "DELETED"
+	return nil
+}
+
+func backupKey(directoryUUID string, backupUUID string) string {
+	return path.Join(directoryUUID, backupUUID+backupFileSuffix)
+}
+
+// createBackup streams pg_dump into the backup store, encrypted when a key is
+// given. The checksum covers the archive as stored.
+func createBackup(ctx context.Context, dockerClient *client.Client, vars *TerraformVars, directoryUUID string, backupUUID string, key []byte) (int64, string, error) {
+
+	reader, writer := io.Pipe()
+	hash := sha256.New()
+
+	go func() {
+		var archive io.WriteCloser = nopWriteCloser{io.MultiWriter(writer, hash)}
+
+		if len(key) > 0 {
+			encrypted, err := backupcrypt.NewWriter(io.MultiWriter(writer, hash), key)
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			archive = encrypted
+		}
+
+		err := execToWriter(ctx, dockerClient, vars.DbContainerName, []string{
+			"pg_dump", "-U", vars.DbUser, "-d", vars.DbName, "-Fc", "-Z", backupCompression,
+		}, archive)
+		if err == nil {
+			err = archive.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	size, err := backupstore.Upload(ctx, BackupStore, backupKey(directoryUUID, backupUUID), reader)
+	reader.CloseWithError(err)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type checksumReader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected string
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(c.hash.Sum(nil)) != c.expected {
+		return n, errChecksumMismatch
+	}
+	return n, err
+}
+
+// openArchive verifies the stored checksum while reading and decrypts the
+// archive when it was written with a key.
+func openArchive(stored io.Reader, key []byte, checksum string) (io.Reader, error) {
+
+	if checksum != "" {
+		stored = &checksumReader{reader: stored, hash: sha256.New(), expected: checksum}
+	}
+
+	if len(key) == 0 {
+		return stored, nil
+	}
+
+	return backupcrypt.NewReader(stored, key)
+}
+
+func serveBackup(w http.ResponseWriter, request *http.Request) {
+
+	directoryUUID, backupUUID, found := strings.Cut(strings.TrimPrefix(request.URL.Path, backupDownloadPath), "/")
+	if !found {
+		http.NotFound(w, request)
+		return
+	}
+
+	if _, err := uuid.Parse(directoryUUID); err != nil {
+		http.NotFound(w, request)
+		return
+	}
+
+	if _, err := uuid.Parse(backupUUID); err != nil {
+		http.NotFound(w, request)
+		return
+	}
+
+	backupFile, size, err := BackupStore.Open(request.Context(), backupKey(directoryUUID, backupUUID))
+	if errors.Is(err, backupstore.ErrNotFound) {
+		http.NotFound(w, request)
+		return
+	}
+	if err != nil {
+		log.Println("Error opening backup:", err)
+		http.Error(w, "backup storage unavailable", http.StatusBadGateway)
+		return
+	}
+	defer backupFile.Close()
+
+	var key []byte
+	if header := request.Header.Get(backupKeyHeader); header != "" {
+		key, err = base64.StdEncoding.DecodeString(header)
+		if err != nil {
+			http.Error(w, "invalid backup key", http.StatusBadRequest)
+			return
+		}
+	}
+
+	archive, err := openArchive(backupFile, key, request.Header.Get(backupChecksumHeader))
+	if err != nil {
+		log.Println("Error opening backup archive:", err)
+		http.Error(w, "backup can't be decrypted", http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if len(key) == 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+
+	// A mismatch only shows up at the end of the archive, by then the status
+	// is sent, so the connection is dropped to keep the download from looking
+	// complete.
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Println("Error serving backup:", err)
+		panic(http.ErrAbortHandler)
+	}
+}
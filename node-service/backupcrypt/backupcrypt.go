@@ -0,0 +1,227 @@
+package backupcrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Archives are a header followed by AES-256-GCM sealed chunks. Each chunk's
+// nonce carries its index and whether it is the last one, so reordered,
+// dropped or truncated chunks fail to open.
+
+const (
+	KeySize = 32
+
+	chunkSize   = 64 * 1024
+	prefixSize  = 7
+	headerMagic = "DBK1"
+)
+
+var (
+	ErrInvalidKey = errors.New("backup key must be 32 bytes")
+	ErrFormat     = errors.New("archive is not an encrypted backup")
+	ErrCorrupted  = errors.New("encrypted backup is corrupted or the key is wrong")
+)
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, index uint32, last bool) []byte {
+
+	nonce := make([]byte, prefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[prefixSize:], index)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+
+	return nonce
+}
+
+type writer struct {
+	writer io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint32
+	buffer []byte
+	closed bool
+}
+
+// NewWriter encrypts everything written to it into w. Close must be called to
+// seal the final chunk; it does not close w.
+func NewWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, prefixSize)
+	_, err = rand.Read(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(append([]byte(headerMagic), prefix...))
+	if err != nil {
+		return nil, err
+	}
+
+	return &writer{
+		writer: w,
+		aead:   aead,
+		prefix: prefix,
+		buffer: make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+
+	if w.closed {
+		return 0, errors.New("write to closed backup writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is only sealed once more data arrives, so the last
+		// chunk is always the one sealed by Close.
+		if len(w.buffer) == chunkSize {
+			err := w.seal(false)
+			if err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(w.buffer[len(w.buffer):chunkSize], p)
+		w.buffer = w.buffer[:len(w.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (w *writer) Close() error {
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.seal(true)
+}
+
+func (w *writer) seal(last bool) error {
+
+	sealed := w.aead.Seal(nil, chunkNonce(w.prefix, w.index, last), w.buffer, nil)
+
+	_, err := w.writer.Write(sealed)
+	if err != nil {
+		return err
+	}
+
+	w.index++
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+type reader struct {
+	reader *bufio.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint32
+	sealed []byte
+	plain  []byte
+	done   bool
+}
+
+// NewReader decrypts an archive written by NewWriter. Reads fail with
+// ErrCorrupted if any chunk was tampered with or the archive was cut short.
+func NewReader(r io.Reader, key []byte) (io.Reader, error) {
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(headerMagic)+prefixSize)
+	_, err = io.ReadFull(r, header)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if string(header[:len(headerMagic)]) != headerMagic {
+		return nil, ErrFormat
+	}
+
+	return &reader{
+		reader: bufio.NewReader(r),
+		aead:   aead,
+		prefix: header[len(headerMagic):],
+		sealed: make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		err := r.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *reader) open() error {
+
+	n, err := io.ReadFull(r.reader, r.sealed)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	last := n < len(r.sealed)
+	if !last {
+		_, err = r.reader.Peek(1)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		last = err == io.EOF
+	}
+
+	plain, err := r.aead.Open(r.sealed[:0], chunkNonce(r.prefix, r.index, last), r.sealed[:n], nil)
+	if err != nil {
+		return ErrCorrupted
+	}
+
+	r.index++
+	r.plain = plain
+	r.done = last
+	return nil
+}
@@ -0,0 +1,159 @@
+package backupstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+
+	// S3 rejects parts smaller than 5 MiB except for the last one.
+	PartSize = 8 * 1024 * 1024
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+type CompletedPart struct {
+	Number int
+	ETag   string
+}
+
+// Store is where backups are kept. Objects are written with multipart uploads
+// so archives never have to fit in memory.
+type Store interface {
+	CreateMultipartUpload(ctx context.Context, key string) (string, error)
+	UploadPart(ctx context.Context, key string, uploadID string, number int, data []byte) (CompletedPart, error)
+	CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key string, uploadID string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, int64, error)
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type Config struct {
+	Driver    string
+	Root      string
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+func ConfigFromEnv() Config {
+	config := Config{
+		Driver:    os.Getenv("BACKUP_STORAGE_DRIVER"),
+		Root:      os.Getenv("BACKUP_STORAGE_ROOT"),
+		Endpoint:  os.Getenv("BACKUP_S3_ENDPOINT"),
+		Bucket:    os.Getenv("BACKUP_S3_BUCKET"),
+		Region:    os.Getenv("BACKUP_S3_REGION"),
+		AccessKey: os.Getenv("BACKUP_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("BACKUP_S3_SECRET_KEY"),
+	}
+
+	if config.Driver == "" {
+		config.Driver = DriverLocal
+	}
+	if config.Root == "" {
+		config.Root = "backups"
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return config
+}
+
+func New(config Config) (Store, error) {
+	switch config.Driver {
+	case DriverLocal:
+		return NewLocal(config.Root)
+	case DriverS3:
+		return NewS3(config.Endpoint, config.Bucket, config.Region, config.AccessKey, config.SecretKey)
+	default:
+		return nil, errors.New("unknown backup storage driver " + config.Driver)
+	}
+}
+
+// Upload streams reader into key in PartSize chunks and returns the number of
+// bytes written. A failed upload is aborted so no partial object is left.
+func Upload(ctx context.Context, store Store, key string, reader io.Reader) (int64, error) {
+
+	uploadID, err := store.CreateMultipartUpload(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	size, parts, err := uploadParts(ctx, store, key, uploadID, reader)
+	if err == nil {
+		err = store.CompleteMultipartUpload(ctx, key, uploadID, parts)
+	}
+	if err != nil {
+		store.AbortMultipartUpload(context.Background(), key, uploadID)
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func uploadParts(ctx context.Context, store Store, key string, uploadID string, reader io.Reader) (int64, []CompletedPart, error) {
+
+	var size int64
+	var parts []CompletedPart
+
+	buffer := make([]byte, PartSize)
+
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(reader, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, nil, err
+		}
+
+		if n > 0 || number == 1 {
+			part, err := store.UploadPart(ctx, key, uploadID, number, buffer[:n])
+			if err != nil {
+				return 0, nil, err
+			}
+
+			parts = append(parts, part)
+			size += int64(n)
+		}
+
+		if n < PartSize {
+			return size, parts, nil
+		}
+	}
+}
+
+// Expire deletes every object under prefix last modified before the cutoff.
+func Expire(ctx context.Context, store Store, prefix string, before time.Time) error {
+
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if !object.LastModified.Before(before) {
+			continue
+		}
+
+		err = store.Delete(ctx, object.Key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
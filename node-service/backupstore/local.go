@@ -0,0 +1,224 @@
+package backupstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const localUploadsDir = ".uploads"
+
+type localStore struct {
+	root string
+}
+
+func NewLocal(root string) (Store, error) {
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Join(root, localUploadsDir), 0750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localStore{root: root}, nil
+}
+
+func (s *localStore) path(key string) (string, error) {
+
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, s.root+string(os.PathSeparator)) || strings.HasPrefix(key, localUploadsDir) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+
+	return path, nil
+}
+
+func (s *localStore) uploadPath(uploadID string) string {
+	return filepath.Join(s.root, localUploadsDir, uploadID)
+}
+
+func (s *localStore) CreateMultipartUpload(ctx context.Context, key string) (string, error) {
+
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	uploadID := hex.EncodeToString(id)
+
+	return uploadID, os.Mkdir(s.uploadPath(uploadID), 0750)
+}
+
+func (s *localStore) UploadPart(ctx context.Context, key string, uploadID string, number int, data []byte) (CompletedPart, error) {
+
+	err := os.WriteFile(filepath.Join(s.uploadPath(uploadID), fmt.Sprintf("%05d", number)), data, 0600)
+	if err != nil {
+		return CompletedPart{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	return CompletedPart{Number: number, ETag: hex.EncodeToString(sum[:])}, nil
+}
+
+func (s *localStore) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []CompletedPart) error {
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0750)
+	if err != nil {
+		return err
+	}
+
+	tempPath := filepath.Join(s.uploadPath(uploadID), "object")
+
+	object, err := os.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		err = appendPart(object, filepath.Join(s.uploadPath(uploadID), fmt.Sprintf("%05d", part.Number)))
+		if err != nil {
+			object.Close()
+			return err
+		}
+	}
+
+	err = object.Sync()
+	object.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(s.uploadPath(uploadID))
+}
+
+func appendPart(object io.Writer, partPath string) error {
+
+	part, err := os.Open(partPath)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	_, err = io.Copy(object, part)
+	return err
+}
+
+func (s *localStore) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+	return os.RemoveAll(s.uploadPath(uploadID))
+}
+
+func (s *localStore) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+
+	return file, info.Size(), nil
+}
+
+func (s *localStore) List(ctx context.Context, prefix string) ([]Object, error) {
+
+	var objects []Object
+
+	// Only walk the directory the prefix points into, not the whole store.
+	start := s.root
+	if index := strings.LastIndex(prefix, "/"); index >= 0 {
+		path, err := s.path(prefix[:index])
+		if err != nil {
+			return nil, err
+		}
+		start = path
+	}
+
+	if _, err := os.Stat(start); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	err := filepath.WalkDir(start, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == localUploadsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relative, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) || strings.HasSuffix(key, ".tmp") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, Object{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+
+	return objects, err
+}
+
+func (s *localStore) Delete(ctx context.Context, key string) error {
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,361 @@
+package backupstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3DateFormat    = "20060102T150405Z"
+	s3UnsignedEmpty = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Store talks to S3 compatible object storage such as MinIO using path
+// style requests signed with AWS Signature Version 4.
+type s3Store struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func NewS3(endpoint string, bucket string, region string, accessKey string, secretKey string) (Store, error) {
+
+	if endpoint == "" || bucket == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("s3 backup storage needs an endpoint, bucket and credentials")
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Store{
+		endpoint:  endpointURL,
+		bucket:    bucket,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{},
+	}, nil
+}
+
+func (s *s3Store) CreateMultipartUpload(ctx context.Context, key string) (string, error) {
+
+	response, err := s.do(ctx, "POST", key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var result initiateMultipartUploadResult
+	err = xml.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+
+	return result.UploadID, nil
+}
+
+func (s *s3Store) UploadPart(ctx context.Context, key string, uploadID string, number int, data []byte) (CompletedPart, error) {
+
+	query := url.Values{
+		"partNumber": {strconv.Itoa(number)},
+		"uploadId":   {uploadID},
+	}
+
+	response, err := s.do(ctx, "PUT", key, query, data)
+	if err != nil {
+		return CompletedPart{}, err
+	}
+	response.Body.Close()
+
+	return CompletedPart{Number: number, ETag: response.Header.Get("ETag")}, nil
+}
+
+func (s *s3Store) CompleteMultipartUpload(ctx context.Context, key string, uploadID string, parts []CompletedPart) error {
+
+	request := completeMultipartUpload{}
+	for _, part := range parts {
+		request.Parts = append(request.Parts, completePart{PartNumber: part.Number, ETag: part.ETag})
+	}
+
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	response, err := s.do(ctx, "POST", key, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// Completing can fail after the 200 status has been sent, in which case
+	// the error is only in the body.
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Contains(data, []byte("<Error>")) {
+		return parseS3Error(response.StatusCode, data)
+	}
+
+	return nil
+}
+
+func (s *s3Store) AbortMultipartUpload(ctx context.Context, key string, uploadID string) error {
+
+	response, err := s.do(ctx, "DELETE", key, url.Values{"uploadId": {uploadID}}, nil)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	return nil
+}
+
+func (s *s3Store) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+
+	response, err := s.do(ctx, "GET", key, nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return response.Body, response.ContentLength, nil
+}
+
+func (s *s3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+
+	var objects []Object
+	var continuationToken string
+
+	for {
+		query := url.Values{
+			"list-type": {"2"},
+			"prefix":    {prefix},
+		}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		response, err := s.do(ctx, "GET", "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(response.Body).Decode(&result)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{Key: content.Key, Size: content.Size, LastModified: content.LastModified})
+		}
+
+		if !result.IsTruncated {
+			return objects, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+
+	response, err := s.do(ctx, "DELETE", key, nil, nil)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	return nil
+}
+
+func (s *s3Store) do(ctx context.Context, method string, key string, query url.Values, body []byte) (*http.Response, error) {
+
+	path := "/" + s.bucket
+	if key != "" {
+		path += "/" + key
+	}
+
+	requestURL := *s.endpoint
+	requestURL.Path = strings.TrimSuffix(s.endpoint.Path, "/") + path
+	requestURL.RawPath = strings.TrimSuffix(s.endpoint.EscapedPath(), "/") + escapePath(path)
+	requestURL.RawQuery = canonicalQuery(query)
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	s.sign(request, requestURL.RawPath, body, time.Now())
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= 300 {
+		defer response.Body.Close()
+
+		if response.StatusCode == http.StatusNotFound && method != "POST" {
+			return nil, ErrNotFound
+		}
+
+		data, _ := io.ReadAll(response.Body)
+		return nil, parseS3Error(response.StatusCode, data)
+	}
+
+	return response, nil
+}
+
+func (s *s3Store) sign(request *http.Request, canonicalPath string, body []byte, now time.Time) {
+
+	now = now.UTC()
+	amzDate := now.Format(s3DateFormat)
+	date := now.Format("20060102")
+
+	payloadHash := s3UnsignedEmpty
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalPath,
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/" + s3Service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath encodes every path segment the way SigV4 expects, which differs
+// from url.PathEscape for characters like '+' and '='.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(query url.Values) string {
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func uriEncode(value string) string {
+
+	var builder strings.Builder
+
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+
+	return builder.String()
+}
+
+func parseS3Error(statusCode int, data []byte) error {
+
+	var s3Err s3Error
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return fmt.Errorf("s3 request failed with %d: %s: %s", statusCode, s3Err.Code, s3Err.Message)
+	}
+
+	return fmt.Errorf("s3 request failed with %d", statusCode)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"log"
+	"node-service/rabbit"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type CloneDatabasePayload struct {
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	User         string
+	UUID         uuid.UUID
+	Limits       ComputeLimits
+	StorageMB    int64
+	SourceURL    string
+	Connectivity string
+}
+
+// CloneDatabase provisions a deployment and loads it with a dump streamed from
+// the source node's export endpoint, the dump never has to fit in memory.
+func (r *RPCServer) CloneDatabase(payload CloneDatabasePayload, reply *CreateDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	ctx, done := r.cancellableDeployment(directoryUUID)
+	defer done()
+
+	tracker := r.trackDeployment(directoryUUID)
+
+	// Like a restore, a clone interrupted before its data is loaded can't be
+	// resumed by applying Terraform again.
+	err := os.MkdirAll(directoryUUID, 0750)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(directoryUUID, restoreMarkerFile), []byte(payload.SourceURL), 0600)
+	}
+	if err != nil {
+		log.Println("Error preparing clone of deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	dbPort, exporterPort, err := r.createDatabase(ctx, payload.Name, payload.Password, payload.User, payload.Type, payload.Version, directoryUUID, payload.Limits, payload.StorageMB, bindAddress(payload.Connectivity))
+
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
+
+	var vars *TerraformVars
+	if err == nil {
+		vars, err = readTerraformVars(directoryUUID)
+		if err != nil {
+			err = newDeploymentError(StepPrepare, CategoryInternal, err)
+		}
+	}
+
+	if err == nil {
+		err = pullDump(ctx, payload.SourceURL, vars)
+		if err != nil {
+			err = newDeploymentError(StepDataTransfer, CategoryDatabase, err)
+		}
+	}
+
+	if err != nil {
+		r.discardDeployment(directoryUUID)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", directoryUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		log.Println("Error cloning deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	os.Remove(filepath.Join(directoryUUID, restoreMarkerFile))
+	tracker.succeed()
+
+	publishMonitoringJob(rabbit.CreateAction, directoryUUID, payload.Type, vars, "")
+
+	(*reply).Status = "CREATED"
+	(*reply).NodeIP = app.MyIP
+	(*reply).NodePort = dbPort
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(directoryUUID))
+	return nil
+}
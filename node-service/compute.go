@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+type ComputeLimits struct {
+	CPU            float64
+	MemoryMB       int64
+	MaxConnections int
+}
+
+type ResizeDatabasePayload struct {
+	UUID   uuid.UUID
+	Limits ComputeLimits
+}
+
+type ResizeDatabaseResponse struct {
+	Status string
+}
+
+func (r *RPCServer) ResizeDatabase(payload ResizeDatabasePayload, reply *ResizeDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	err := r.applyComputeLimits(context.Background(), directoryUUID, payload.Limits)
+	if err != nil {
+		log.Println("Error resizing database:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "RESIZED"
+	return nil
+}
+
+// applyComputeLimits keeps the CPU and memory limits in the terraform
+// variables, so an apply that replaces the container doesn't drop them.
+// max_connections lives in the data directory and survives either way, the
+// database is only restarted when it actually changes.
+func (r *RPCServer) applyComputeLimits(ctx context.Context, directoryUUID string, limits ComputeLimits) error {
+
+	if limits.CPU <= 0 || limits.MemoryMB <= 0 {
+		return nil
+	}
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return err
+	}
+
+	if vars.CPU != limits.CPU || vars.MemoryMB != limits.MemoryMB {
+		vars.CPU = limits.CPU
+		vars.MemoryMB = limits.MemoryMB
+
+		err = writeTerraformVars(directoryUUID, *vars)
+		if err != nil {
+			return err
+		}
+
+		err = r.terraformApply(ctx, directoryUUID, "-target=docker_container.example_db")
+		if err != nil {
+			return err
+		}
+	}
+
+	if limits.MaxConnections <= 0 {
+		return nil
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	err = waitForDatabase(ctx, dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName)
+	if err != nil {
+		return err
+	}
+
+	current, err := execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName, "SHOW max_connections")
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(current) == strconv.Itoa(limits.MaxConnections) {
+		return nil
+	}
+
+	_, err = execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName, fmt.Sprintf("ALTER SYSTEM SET max_connections = %d", limits.MaxConnections))
+	if err != nil {
+		return err
+	}
+
+	err = dockerClient.ContainerRestart(ctx, vars.DbContainerName, container.StopOptions{})
+	if err != nil {
+		return err
+	}
+
+	return waitForDatabase(ctx, dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName)
+}
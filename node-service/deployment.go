@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/go-redis/redis"
+)
+
+const (
+	DeploymentInProgress = "IN_PROGRESS"
+	DeploymentSucceeded  = "SUCCEEDED"
+	DeploymentFailed     = "FAILED"
+	DeploymentCancelled  = "CANCELLED"
+
+	StepPrepare        = "PREPARE"
+	StepTerraformInit  = "TERRAFORM_INIT"
+	StepStorage        = "STORAGE"
+	StepTerraformApply = "TERRAFORM_APPLY"
+	StepContainer      = "CONTAINER"
+	StepDatabase       = "DATABASE"
+	StepComputeLimits  = "COMPUTE_LIMITS"
+	StepDataTransfer   = "DATA_TRANSFER"
+	StepRecovery       = "RECOVERY"
+	StepReplication    = "REPLICATION"
+	StepReady          = "READY"
+
+	CategoryTerraform = "TERRAFORM"
+	CategoryStorage   = "STORAGE"
+	CategoryDocker    = "DOCKER"
+	CategoryDatabase  = "DATABASE"
+	CategoryInternal  = "INTERNAL"
+
+	stderrTailLines = 20
+)
+
+type DeploymentStatus struct {
+	State   string           `json:"state"`
+	Step    string           `json:"step"`
+	Message string           `json:"message"`
+	Percent int              `json:"percent"`
+	Error   *DeploymentError `json:"error,omitempty"`
+}
+
+type DeploymentError struct {
+	Step     string `json:"step"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+	Output   string `json:"output,omitempty"`
+}
+
+func (e *DeploymentError) Error() string {
+	return e.Step + ": " + e.Message
+}
+
+func newDeploymentError(step string, category string, err error) *DeploymentError {
+
+	deploymentError := &DeploymentError{
+		Step:     step,
+		Category: category,
+		Message:  err.Error(),
+	}
+
+	var commandError *CommandError
+	if errors.As(err, &commandError) {
+		deploymentError.Output = commandError.Output
+	}
+
+	return deploymentError
+}
+
+type CommandError struct {
+	Err    error
+	Output string
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+func stderrTail(output string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > stderrTailLines {
+		lines = lines[len(lines)-stderrTailLines:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type StatusLogsPair struct {
+	Log    string
+	Status DeploymentStatus
+}
+
+var statusLogs = []StatusLogsPair{
+	{
+		Log:    "CREATE DATABASE",
+		Status: DeploymentStatus{State: DeploymentInProgress, Step: StepDatabase, Message: "Database created successfully...", Percent: 70},
+	},
+	{
+		Log:    "Starting PostgreSQL",
+		Status: DeploymentStatus{State: DeploymentInProgress, Step: StepDatabase, Message: "Starting database...", Percent: 80},
+	},
+	{
+		Log:    "database system is ready to accept connections",
+		Status: DeploymentStatus{State: DeploymentInProgress, Step: StepDatabase, Message: "Database is accepting connections...", Percent: 90},
+	},
+}
+
+type deploymentTracker struct {
+	mtx            sync.Mutex
+	finished       bool
+	lastPercent    int
+	deploymentUUID string
+	redisClient    *redis.Client
+}
+
+func (r *RPCServer) trackDeployment(deploymentUUID string) *deploymentTracker {
+
+	tracker := &deploymentTracker{
+		deploymentUUID: deploymentUUID,
+		redisClient:    r.redisClient,
+	}
+
+	tracker.set(DeploymentStatus{State: DeploymentInProgress, Step: StepPrepare, Message: "Preparing terraform file for deployment...", Percent: 20})
+	go tracker.watch()
+
+	return tracker
+}
+
+func (r *RPCServer) deploymentState(deploymentUUID string) string {
+
+	data, err := r.redisClient.Get(deploymentUUID).Bytes()
+	if err != nil {
+		return ""
+	}
+
+	var status DeploymentStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return ""
+	}
+
+	return status.State
+}
+
+func (t *deploymentTracker) set(status DeploymentStatus) {
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.finished || (status.State == DeploymentInProgress && status.Percent <= t.lastPercent) {
+		return
+	}
+
+	t.lastPercent = status.Percent
+	t.finished = status.State != DeploymentInProgress
+
+	data, _ := json.Marshal(status)
+	err := t.redisClient.Set(t.deploymentUUID, data, 0).Err()
+	if err != nil {
+		log.Println("Error writing deployment status:", err)
+	}
+}
+
+func (t *deploymentTracker) isFinished() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.finished
+}
+
+func (t *deploymentTracker) succeed() {
+	t.set(DeploymentStatus{State: DeploymentSucceeded, Step: StepReady, Message: "Deployment finished successfully...", Percent: 100})
+}
+
+func (t *deploymentTracker) cancel() {
+
+	t.mtx.Lock()
+	percent := t.lastPercent
+	t.mtx.Unlock()
+
+	t.set(DeploymentStatus{State: DeploymentCancelled, Step: StepPrepare, Message: "Deployment cancelled...", Percent: percent})
+}
+
+func (t *deploymentTracker) fail(err error) {
+
+	var deploymentError *DeploymentError
+	if !errors.As(err, &deploymentError) {
+		deploymentError = newDeploymentError(StepPrepare, CategoryInternal, err)
+	}
+
+	t.mtx.Lock()
+	percent := t.lastPercent
+	t.mtx.Unlock()
+
+	t.set(DeploymentStatus{
+		State:   DeploymentFailed,
+		Step:    deploymentError.Step,
+		Message: "Deployment failed...",
+		Percent: percent,
+		Error:   deploymentError,
+	})
+}
+
+func (t *deploymentTracker) watch() {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		return
+	}
+	defer dockerClient.Close()
+
+	ctx := context.Background()
+
+	for !t.isFinished() {
+
+		_, err = dockerClient.ContainerInspect(ctx, t.deploymentUUID)
+		if err == nil {
+			break
+		}
+
+		if !client.IsErrNotFound(err) {
+			log.Println("Error inspecting container")
+			return
+		}
+
+		time.Sleep(time.Second)
+	}
+
+	t.set(DeploymentStatus{State: DeploymentInProgress, Step: StepContainer, Message: "Deployment of container started...", Percent: 40})
+	t.set(DeploymentStatus{State: DeploymentInProgress, Step: StepDatabase, Message: "Preparing database...", Percent: 60})
+
+	for !t.isFinished() {
+
+		out, err := dockerClient.ContainerLogs(ctx, t.deploymentUUID, container.LogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Follow:     false,
+			Tail:       "10",
+		})
+		if err != nil {
+			log.Printf("Error getting container logs: %v", err)
+			return
+		}
+
+		var buffer bytes.Buffer
+
+		scanner := bufio.NewScanner(out)
+		for scanner.Scan() {
+			buffer.WriteString(scanner.Text())
+		}
+		out.Close()
+
+		logsDump := buffer.String()
+
+		for _, sl := range statusLogs {
+			if strings.Contains(logsDump, sl.Log) {
+				t.set(sl.Status)
+			}
+		}
+
+		if strings.Contains(logsDump, statusLogs[len(statusLogs)-1].Log) {
+			return
+		}
+
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// deploymentNetwork is the network every database and exporter is attached to,
+// it has to match the default of network_name in the terraform templates.
+const deploymentNetwork = "dbaas-deployments"
+
+// ensureDeploymentNetwork creates the network deployments talk to each other
+// over, so a database bound to loopback is still reachable by its exporter
+// and replicas.
+func ensureDeploymentNetwork(ctx context.Context) error {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	_, err = dockerClient.NetworkInspect(ctx, deploymentNetwork, types.NetworkInspectOptions{})
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return err
+	}
+
+	_, err = dockerClient.NetworkCreate(ctx, deploymentNetwork, types.NetworkCreate{Driver: "bridge"})
+	return err
+}
+
+func execInContainer(ctx context.Context, dockerClient *client.Client, containerName string, cmd []string) (string, error) {
+
+	var stdout bytes.Buffer
+
+	err := execToWriter(ctx, dockerClient, containerName, cmd, &stdout)
+	return stdout.String(), err
+}
+
+func execToWriter(ctx context.Context, dockerClient *client.Client, containerName string, cmd []string, stdout io.Writer) error {
+
+	execID, err := dockerClient.ContainerExecCreate(ctx, containerName, types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	attach, err := dockerClient.ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
+	if err != nil {
+		return err
+	}
+	defer attach.Close()
+
+	var stderr bytes.Buffer
+	_, err = stdcopy.StdCopy(stdout, &stderr, attach.Reader)
+	if err != nil {
+		return err
+	}
+
+	inspect, err := dockerClient.ContainerExecInspect(ctx, execID.ID)
+	if err != nil {
+		return err
+	}
+
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("command %q exited with code %d: %s", strings.Join(cmd, " "), inspect.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+func execSQL(ctx context.Context, dockerClient *client.Client, containerName, dbUser, dbName, query string) (string, error) {
+	return execInContainer(ctx, dockerClient, containerName, []string{
+		"psql", "-U", dbUser, "-d", dbName, "-v", "ON_ERROR_STOP=1", "-tAc", query,
+	})
+}
+
+// quoteIdentifier and quoteLiteral make names that come from users safe to
+// put into queries run through execSQL.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func waitForDatabase(ctx context.Context, dockerClient *client.Client, containerName, dbUser, dbName string) error {
+	retryCounts := 0
+	maxCounts := 60
+
+	for {
+		_, err := execInContainer(ctx, dockerClient, containerName, []string{
+			"pg_isready", "-h", "localhost", "-U", dbUser, "-d", dbName,
+		})
+		if err == nil {
+			return nil
+		}
+
+		retryCounts++
+		if retryCounts > maxCounts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
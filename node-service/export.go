@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	exportPath        = "/exports/"
+	exportErrorHeader = "X-Export-Error"
+)
+
+var exportFormats = map[string]string{
+	"custom":        "c",
+	"plain":         "p",
+	"directory-tar": "t",
+}
+
+// countingWriter remembers whether anything reached the client, after that
+// an error can only be reported in the trailer.
+type countingWriter struct {
+	writer  http.ResponseWriter
+	written int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	n, err := w.writer.Write(data)
+	w.written += int64(n)
+	return n, err
+}
+
+// serveExport streams a live pg_dump of the deployment. The tar format is the
+// directory format packed into a single stream, since a directory can't be.
+func serveExport(w http.ResponseWriter, request *http.Request) {
+
+	directoryUUID := strings.TrimPrefix(request.URL.Path, exportPath)
+	if _, err := uuid.Parse(directoryUUID); err != nil {
+		http.NotFound(w, request)
+		return
+	}
+
+	query := request.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = "custom"
+	}
+
+	formatFlag, ok := exportFormats[format]
+	if !ok {
+		http.Error(w, "unsupported format "+format, http.StatusBadRequest)
+		return
+	}
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		http.NotFound(w, request)
+		return
+	}
+
+	cmd := []string{"pg_dump", "-U", vars.DbUser, "-d", vars.DbName, "-F" + formatFlag}
+	for _, table := range query["table"] {
+		cmd = append(cmd, "--table="+table)
+	}
+	for _, table := range query["exclude_table"] {
+		cmd = append(cmd, "--exclude-table="+table)
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer dockerClient.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Trailer", exportErrorHeader)
+
+	output := &countingWriter{writer: w}
+
+	err = execToWriter(request.Context(), dockerClient, vars.DbContainerName, cmd, output)
+	if err == nil {
+		return
+	}
+
+	log.Println("Error exporting deployment", directoryUUID, err)
+
+	if output.written == 0 {
+		w.Header().Del("Trailer")
+		http.Error(w, stderrTail(err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(exportErrorHeader, strings.ReplaceAll(stderrTail(err.Error()), "\n", " "))
+}
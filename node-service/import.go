@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	importUploadPath   = "/imports/"
+	importFilePrefix   = "import-"
+	customDumpMagic    = "PGDMP"
+	importFormatPlain  = "PLAIN"
+	importFormatCustom = "CUSTOM"
+)
+
+type ImportDatabasePayload struct {
+	UUID    uuid.UUID
+	JobUUID uuid.UUID
+}
+
+type ImportDatabaseResponse struct {
+	Status string
+	Format string
+	Error  string
+}
+
+func importFilePath(vars *TerraformVars, jobUUID string) string {
+	return filepath.Join(vars.DataPath, importFilePrefix+jobUUID+backupFileSuffix)
+}
+
+// receiveImport stores an uploaded dump on the deployment's volume, so it
+// counts against the database's storage like the data it will turn into.
+func receiveImport(w http.ResponseWriter, request *http.Request) {
+
+	if request.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	directoryUUID, jobUUID, found := strings.Cut(strings.TrimPrefix(request.URL.Path, importUploadPath), "/")
+	if !found {
+		http.NotFound(w, request)
+		return
+	}
+
+	if _, err := uuid.Parse(directoryUUID); err != nil {
+		http.NotFound(w, request)
+		return
+	}
+
+	if _, err := uuid.Parse(jobUUID); err != nil {
+		http.NotFound(w, request)
+		return
+	}
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		http.NotFound(w, request)
+		return
+	}
+
+	path := importFilePath(vars, jobUUID)
+	tempPath := path + ".tmp"
+
+	importFile, err := os.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Println("Error creating import file:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tempPath)
+
+	_, err = io.Copy(importFile, request.Body)
+	if err == nil {
+		err = importFile.Sync()
+	}
+	importFile.Close()
+	if err != nil {
+		log.Println("Error receiving import:", err)
+		http.Error(w, err.Error(), http.StatusInsufficientStorage)
+		return
+	}
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *RPCServer) ImportDatabase(payload ImportDatabasePayload, reply *ImportDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	path := importFilePath(vars, payload.JobUUID.String())
+	defer os.Remove(path)
+
+	format, err := detectImportFormat(path)
+	if err != nil {
+		log.Println("Error reading uploaded import:", err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+	(*reply).Format = format
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+	defer dockerClient.Close()
+
+	err = importDump(context.Background(), dockerClient, vars, containerDataDir+"/"+filepath.Base(path), format)
+	if err != nil {
+		log.Println("Error importing into deployment", directoryUUID, err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = stderrTail(err.Error())
+		return nil
+	}
+
+	(*reply).Status = "IMPORTED"
+	return nil
+}
+
+func detectImportFormat(path string) (string, error) {
+
+	importFile, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer importFile.Close()
+
+	magic, err := bufio.NewReader(importFile).Peek(len(customDumpMagic))
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	if string(magic) == customDumpMagic {
+		return importFormatCustom, nil
+	}
+	return importFormatPlain, nil
+}
+
+// importDump loads the dump in a single transaction, so a failed import
+// leaves the database as it was.
+func importDump(ctx context.Context, dockerClient *client.Client, vars *TerraformVars, containerPath string, format string) error {
+
+	cmd := []string{
+		"psql", "-U", vars.DbUser, "-d", vars.DbName, "-v", "ON_ERROR_STOP=1", "--single-transaction", "-q", "-f", containerPath,
+	}
+	if format == importFormatCustom {
+		cmd = []string{
+			"pg_restore", "-U", vars.DbUser, "-d", vars.DbName, "--no-owner", "--no-acl", "--single-transaction", "--exit-on-error", containerPath,
+		}
+	}
+
+	_, err := execInContainer(ctx, dockerClient, vars.DbContainerName, cmd)
+	return err
+}
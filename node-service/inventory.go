@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	ContainerRoleDatabase = "DATABASE"
+	ContainerRoleExporter = "EXPORTER"
+)
+
+type ListContainersPayload struct{}
+
+type ContainerInfo struct {
+	Name           string
+	DeploymentUUID string
+	Role           string
+	State          string
+}
+
+type ListContainersResponse struct {
+	Status     string
+	Containers []ContainerInfo
+}
+
+func (r *RPCServer) ListContainers(payload ListContainersPayload, reply *ListContainersResponse) error {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	defer dockerClient.Close()
+
+	containers, err := dockerClient.ContainerList(context.Background(), container.ListOptions{All: true})
+	if err != nil {
+		log.Println("Error listing containers:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	for _, c := range containers {
+		for _, name := range c.Names {
+			name = strings.TrimPrefix(name, "/")
+
+			deploymentUUID, role := strings.TrimSuffix(name, "exporter"), ContainerRoleExporter
+			if deploymentUUID == name {
+				role = ContainerRoleDatabase
+			}
+
+			if _, err := uuid.Parse(deploymentUUID); err != nil {
+				continue
+			}
+
+			(*reply).Containers = append((*reply).Containers, ContainerInfo{
+				Name:           name,
+				DeploymentUUID: deploymentUUID,
+				Role:           role,
+				State:          c.State,
+			})
+		}
+	}
+
+	(*reply).Status = "OK"
+	return nil
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
 	"net/http"
 	"net/rpc"
+	"node-service/backupstore"
 	"node-service/rabbit"
 	"node-service/utils"
 	"os"
@@ -17,6 +19,9 @@ import (
 type RPCServer struct {
 	portMtx      sync.Mutex
 	portReserved map[int]int
+	deployMtx    sync.Mutex
+	deployLocks  map[string]*deploymentLock
+	cancels      map[string]context.CancelFunc
 	redisClient  *redis.Client
 }
 
@@ -32,6 +37,7 @@ var app *App
 
 var RabbitConnection *amqp.Connection
 var Publisher *rabbit.Publisher
+var BackupStore backupstore.Store
 
 const (
 	queueName string = "monitoring_queue"
@@ -40,10 +46,30 @@ const (
 func main() {
 
 	utils.InitUrl()
+	mountVolumes()
 
-	rpc.Register(NewRPCServer())
+	store, err := backupstore.New(backupstore.ConfigFromEnv())
+	if err != nil {
+		log.Println("Can't create backup storage:", err)
+		os.Exit(1)
+	}
+	BackupStore = store
+
+	err = ensureDeploymentNetwork(context.Background())
+	if err != nil {
+		log.Println("Can't create deployment network:", err)
+		os.Exit(1)
+	}
+
+	rpcServer := NewRPCServer()
+	rpc.Register(rpcServer)
 	rpc.HandleHTTP()
+	http.HandleFunc(backupDownloadPath, serveBackup)
+	http.HandleFunc(importUploadPath, receiveImport)
+	http.HandleFunc(exportPath, serveExport)
 	go app.listenRPC()
+	go rpcServer.watchStorage()
+	go rpcServer.watchArchives()
 
 	app = &App{
 		MyIP: "192.168.1.11:3000",
@@ -70,6 +96,8 @@ func main() {
 func NewRPCServer() *RPCServer {
 	return &RPCServer{
 		portReserved: make(map[int]int),
+		deployLocks:  make(map[string]*deploymentLock),
+		cancels:      make(map[string]context.CancelFunc),
 		redisClient: redis.NewClient(&redis.Options{
 			Addr:     utils.URL.RedisServiceUrl,
 			Password: "",
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"node-service/rabbit"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	ConnectivityPublic  = "Public"
+	ConnectivityPrivate = "Private"
+)
+
+type UpdateDatabasePayload struct {
+	UUID         uuid.UUID
+	Type         string
+	GrafanaUID   string
+	Name         string
+	Connectivity string
+}
+
+type UpdateDatabaseResponse struct {
+	Status string
+}
+
+func (r *RPCServer) UpdateDatabase(payload UpdateDatabasePayload, reply *UpdateDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	defer dockerClient.Close()
+
+	ctx := context.Background()
+
+	renamed := false
+	oldVars := *vars
+
+	if payload.Name != "" && payload.Name != vars.DbName {
+		err = dockerClient.ContainerStop(ctx, vars.ExporterContainerName, container.StopOptions{})
+		if err != nil {
+			log.Println("Error stopping exporter before rename:", err)
+			(*reply).Status = "ERROR"
+			return nil
+		}
+
+		err = renameDatabase(ctx, dockerClient, vars, payload.Name)
+		if err != nil {
+			log.Println("Error renaming database:", err)
+			dockerClient.ContainerStart(ctx, vars.ExporterContainerName, container.StartOptions{})
+			(*reply).Status = "ERROR"
+			return nil
+		}
+
+		renamed = true
+		vars.DbName = payload.Name
+
+		err = writeTerraformVars(directoryUUID, *vars)
+		if err != nil {
+			log.Println("Error writing terraform variables for deployment:", err)
+			r.undoRename(ctx, dockerClient, directoryUUID, &oldVars, payload.Name)
+			(*reply).Status = "ERROR"
+			return nil
+		}
+
+		err = r.terraformApply(ctx, directoryUUID, "-target=docker_container.postgres_exporter")
+		if err != nil {
+			log.Println("Failed to update exporter data source:", err)
+			r.undoRename(ctx, dockerClient, directoryUUID, &oldVars, payload.Name)
+			(*reply).Status = "ERROR"
+			return nil
+		}
+
+	}
+
+	if payload.Connectivity != "" {
+		bindIP := bindAddress(payload.Connectivity)
+
+		if bindIP != vars.DbBindIP {
+			vars.DbBindIP = bindIP
+
+			err = r.applyConnectivity(ctx, dockerClient, directoryUUID, vars)
+			if err != nil {
+				log.Println("Failed to apply connectivity change:", err)
+
+				// The config service keeps the old metadata on an error, so
+				// the deployment has to go back to it as well.
+				vars.DbBindIP = oldVars.DbBindIP
+				err = r.applyConnectivity(ctx, dockerClient, directoryUUID, vars)
+				if err != nil {
+					log.Println("Error restoring connectivity of", directoryUUID, err)
+				}
+
+				if renamed {
+					r.undoRename(ctx, dockerClient, directoryUUID, &oldVars, payload.Name)
+				}
+
+				(*reply).Status = "ERROR"
+				return nil
+			}
+		}
+	}
+
+	if renamed {
+		publishMonitoringJob(rabbit.DeleteAction, directoryUUID, payload.Type, &oldVars, payload.GrafanaUID)
+		publishMonitoringJob(rabbit.CreateAction, directoryUUID, payload.Type, vars, "")
+	}
+
+	(*reply).Status = "UPDATED"
+	return nil
+}
+
+func (r *RPCServer) applyConnectivity(ctx context.Context, dockerClient *client.Client, directoryUUID string, vars *TerraformVars) error {
+
+	err := writeTerraformVars(directoryUUID, *vars)
+	if err != nil {
+		return err
+	}
+
+	err = r.terraformApply(ctx, directoryUUID, "-target=docker_container.example_db")
+	if err != nil {
+		return err
+	}
+
+	return waitForDatabase(ctx, dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName)
+}
+
+// undoRename puts the old database name back after the exporter couldn't be
+// moved to the new one, and brings the exporter back up on the old name.
+func (r *RPCServer) undoRename(ctx context.Context, dockerClient *client.Client, directoryUUID string, oldVars *TerraformVars, name string) {
+
+	renamed := *oldVars
+	renamed.DbName = name
+
+	err := renameDatabase(ctx, dockerClient, &renamed, oldVars.DbName)
+	if err != nil {
+		log.Println("Error renaming database", directoryUUID, "back to", oldVars.DbName, err)
+		return
+	}
+
+	err = writeTerraformVars(directoryUUID, *oldVars)
+	if err != nil {
+		log.Println("Error restoring terraform variables for deployment:", err)
+		return
+	}
+
+	err = r.terraformApply(ctx, directoryUUID, "-target=docker_container.postgres_exporter")
+	if err != nil {
+		log.Println("Failed to restore exporter of", directoryUUID, err)
+		return
+	}
+
+	err = dockerClient.ContainerStart(ctx, oldVars.ExporterContainerName, container.StartOptions{})
+	if err != nil {
+		log.Println("Error starting exporter of", directoryUUID, err)
+	}
+}
+
+// bindAddress is the host address the database port is published on. Private
+// databases are only reachable from the node itself, their exporter and
+// replicas use the deployment network.
+func bindAddress(connectivity string) string {
+	if connectivity == ConnectivityPrivate {
+		return "127.0.0.1"
+	}
+	return ""
+}
+
+func renameDatabase(ctx context.Context, dockerClient *client.Client, vars *TerraformVars, name string) error {
+
+	query := fmt.Sprintf("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = %s AND pid <> pg_backend_pid()", quoteLiteral(vars.DbName))
+	_, err := execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, "postgres", query)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", quoteIdentifier(vars.DbName), quoteIdentifier(name))
+	_, err = execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, "postgres", query)
+	return err
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"node-service/rabbit"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+type SetReadOnlyPayload struct {
+	UUID     uuid.UUID
+	ReadOnly bool
+}
+
+type SetReadOnlyResponse struct {
+	Status string
+}
+
+type DeploymentCredentialsPayload struct {
+	UUID uuid.UUID
+}
+
+type DeploymentCredentialsResponse struct {
+	Status   string
+	Name     string
+	User     string
+	Password string
+}
+
+type MigrateDatabasePayload struct {
+	UUID         uuid.UUID
+	SourceURL    string
+	Name         string
+	User         string
+	Password     string
+	Type         string
+	Version      string
+	Limits       ComputeLimits
+	StorageMB    int64
+	Connectivity string
+}
+
+type CompleteMigrationPayload struct {
+	UUID uuid.UUID
+	Type string
+}
+
+type CompleteMigrationResponse struct {
+	Status string
+}
+
+func (r *RPCServer) SetReadOnly(payload SetReadOnlyPayload, reply *SetReadOnlyResponse) error {
+
+	err := r.setReadOnly(payload.UUID.String(), payload.ReadOnly)
+	if err != nil {
+		log.Println("Error changing read only mode for deployment", payload.UUID, err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "OK"
+	return nil
+}
+
+// DeploymentCredentials lets a deployment be recreated elsewhere with the
+// same connection details, the config service only keeps a password hash.
+func (r *RPCServer) DeploymentCredentials(payload DeploymentCredentialsPayload, reply *DeploymentCredentialsResponse) error {
+
+	vars, err := readTerraformVars(payload.UUID.String())
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "OK"
+	(*reply).Name = vars.DbName
+	(*reply).User = vars.DbUser
+	(*reply).Password = vars.DbPassword
+	return nil
+}
+
+// MigrateDatabase provisions a deployment on this node and loads it with a
+// dump streamed from the source node's export endpoint.
+func (r *RPCServer) MigrateDatabase(payload MigrateDatabasePayload, reply *CreateDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	ctx, done := r.cancellableDeployment(directoryUUID)
+	defer done()
+
+	tracker := r.trackDeployment(directoryUUID)
+
+	dbPort, exporterPort, err := r.createDatabase(ctx, payload.Name, payload.Password, payload.User, payload.Type, payload.Version, directoryUUID, payload.Limits, payload.StorageMB, bindAddress(payload.Connectivity))
+
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
+
+	var vars *TerraformVars
+	if err == nil {
+		vars, err = readTerraformVars(directoryUUID)
+		if err != nil {
+			err = newDeploymentError(StepPrepare, CategoryInternal, err)
+		}
+	}
+
+	if err == nil {
+		err = pullDump(ctx, payload.SourceURL, vars)
+		if err != nil {
+			err = newDeploymentError(StepDataTransfer, CategoryDatabase, err)
+		}
+	}
+
+	if err != nil {
+		r.discardDeployment(directoryUUID)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", directoryUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		log.Println("Error migrating deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	tracker.succeed()
+
+	(*reply).Status = "CREATED"
+	(*reply).NodeIP = app.MyIP
+	(*reply).NodePort = dbPort
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(directoryUUID))
+	return nil
+}
+
+// CompleteMigration starts monitoring the migrated deployment once the config
+// service points at it, so the new dashboard is attached to the right entry.
+func (r *RPCServer) CompleteMigration(payload CompleteMigrationPayload, reply *CompleteMigrationResponse) error {
+
+	vars, err := readTerraformVars(payload.UUID.String())
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	publishMonitoringJob(rabbit.CreateAction, payload.UUID.String(), payload.Type, vars, "")
+
+	(*reply).Status = "COMPLETED"
+	return nil
+}
+
+// trailerReader turns an export that failed after it started streaming into
+// a read error instead of a clean, truncated EOF.
+type trailerReader struct {
+	response *http.Response
+}
+
+func (t *trailerReader) Read(data []byte) (int, error) {
+	n, err := t.response.Body.Read(data)
+	if err == io.EOF {
+		if exportError := t.response.Trailer.Get(exportErrorHeader); exportError != "" {
+			return n, errors.New("source export failed: " + exportError)
+		}
+	}
+	return n, err
+}
+
+func pullDump(ctx context.Context, sourceURL string, vars *TerraformVars) error {
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		return errors.New("source export failed: " + string(body))
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	return restoreDatabase(ctx, dockerClient, vars, &trailerReader{response: response})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+type ChangePasswordPayload struct {
+	UUID     uuid.UUID
+	Password string
+}
+
+type ChangePasswordResponse struct {
+	Status  string
+	Warning string
+}
+
+func (r *RPCServer) ChangePassword(payload ChangePasswordPayload, reply *ChangePasswordResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	defer dockerClient.Close()
+
+	// A replica is read only and gets the new password from its primary, only
+	// its exporter has to follow.
+	if replicaPrimary(directoryUUID) == "" {
+		query := fmt.Sprintf("ALTER USER %s WITH PASSWORD %s", quoteIdentifier(vars.DbUser), quoteLiteral(payload.Password))
+		_, err = execSQL(context.Background(), dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName, query)
+		if err != nil {
+			log.Println("Error changing database password:", err)
+			(*reply).Status = "ERROR"
+			return nil
+		}
+	}
+
+	// The password is changed from here on, failing now would leave the caller
+	// with the old one, so the exporter is only reported as a warning.
+	(*reply).Status = "CHANGED"
+
+	vars.DbPassword = payload.Password
+
+	err = writeTerraformVars(directoryUUID, *vars)
+	if err != nil {
+		log.Println("Error writing terraform variables for deployment:", err)
+		(*reply).Warning = "Password changed, but monitoring still uses the old one"
+		return nil
+	}
+
+	err = r.terraformApply(context.Background(), directoryUUID, "-target=docker_container.postgres_exporter")
+	if err != nil {
+		log.Println("Failed to update exporter data source:", err)
+		(*reply).Warning = "Password changed, but monitoring still uses the old one"
+		return nil
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+type PowerStatePayload struct {
+	UUID uuid.UUID
+}
+
+type PowerStateResponse struct {
+	Status string
+}
+
+func (r *RPCServer) StopDatabase(payload PowerStatePayload, reply *PowerStateResponse) error {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	defer dockerClient.Close()
+
+	ctx := context.Background()
+	dbContainer := payload.UUID.String()
+
+	for _, containerName := range []string{dbContainer + "exporter", dbContainer} {
+		err = dockerClient.ContainerStop(ctx, containerName, container.StopOptions{})
+		if err != nil {
+			log.Printf("Error stopping container %s: %v", containerName, err)
+			(*reply).Status = "ERROR"
+			return nil
+		}
+	}
+
+	(*reply).Status = "STOPPED"
+	return nil
+}
+
+func (r *RPCServer) StartDatabase(payload PowerStatePayload, reply *PowerStateResponse) error {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	defer dockerClient.Close()
+
+	ctx := context.Background()
+	dbContainer := payload.UUID.String()
+
+	for _, containerName := range []string{dbContainer, dbContainer + "exporter"} {
+		err = dockerClient.ContainerStart(ctx, containerName, container.StartOptions{})
+		if err != nil {
+			log.Printf("Error starting container %s: %v", containerName, err)
+			(*reply).Status = "ERROR"
+			return nil
+		}
+	}
+
+	(*reply).Status = "ONLINE"
+	return nil
+}
+
+func (r *RPCServer) RestartDatabase(payload PowerStatePayload, reply *PowerStateResponse) error {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	defer dockerClient.Close()
+
+	ctx := context.Background()
+	dbContainer := payload.UUID.String()
+
+	for _, containerName := range []string{dbContainer, dbContainer + "exporter"} {
+		err = dockerClient.ContainerRestart(ctx, containerName, container.StopOptions{})
+		if err != nil {
+			log.Printf("Error restarting container %s: %v", containerName, err)
+			(*reply).Status = "ERROR"
+			return nil
+		}
+	}
+
+	(*reply).Status = "ONLINE"
+	return nil
+}
@@ -1,9 +1,16 @@
 package rabbit
 
+const (
+	CreateAction string = "CREATE"
+	DeleteAction string = "DELETE"
+)
+
 type Job struct {
+	Action        string
 	DashboardName string
 	DbType        string
 	NodeIP        string
 	NodePort      string
 	Datname       string
+	GrafanaUID    string
 }
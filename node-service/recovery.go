@@ -0,0 +1,859 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"node-service/backupstore"
+	"node-service/rabbit"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	walArchiveDir      = "wal"
+	baseBackupsDir     = "base"
+	baseBackupSuffix   = ".tar.gz"
+	restoreWalDir      = "restore-wal"
+	restoreMarkerFile  = "restore.pending"
+	containerPgDataDir = containerDataDir + "/pgdata"
+
+	// uid of the postgres user in the official images, the archiver runs as
+	// it and has to be able to write into the archive directory.
+	postgresUID = 999
+
+	archiveCheckInterval = 10 * time.Minute
+	baseBackupInterval   = 24 * time.Hour
+	baseBackupsRetained  = 7
+	walSwitchTimeout     = time.Minute
+	recoveryTimeout      = 30 * time.Minute
+
+	recoveryTargetNotReachedLog = "recovery ended before configured recovery target was reached"
+)
+
+var errRecoveryTargetNotReached = errors.New(recoveryTargetNotReachedLog)
+
+type RecoveryWindowPayload struct {
+	UUID uuid.UUID
+}
+
+type RecoveryWindowResponse struct {
+	Status     string
+	EarliestAt time.Time
+	LatestAt   time.Time
+}
+
+type RestoreDatabasePayload struct {
+	SourceUUID   uuid.UUID
+	UUID         uuid.UUID
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	TargetTime   time.Time
+	Limits       ComputeLimits
+	StorageMB    int64
+	Connectivity string
+}
+
+type baseBackup struct {
+	Path       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+func (r *RPCServer) RecoveryWindow(payload RecoveryWindowPayload, reply *RecoveryWindowResponse) error {
+
+	directoryUUID := payload.UUID.String()
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	if vars.WalArchivePath == "" {
+		(*reply).Status = "NOT_ARCHIVED"
+		return nil
+	}
+
+	backups, err := listBaseBackups(directoryUUID)
+	if err != nil || len(backups) == 0 {
+		(*reply).Status = "NOT_ARCHIVED"
+		return nil
+	}
+
+	latest, err := flushWalArchive(context.Background(), vars, directoryUUID)
+	if err != nil {
+		log.Println("Error flushing WAL archive, using what is archived:", err)
+		latest, err = latestArchivedWal(directoryUUID)
+		if err != nil {
+			(*reply).Status = "ERROR"
+			return nil
+		}
+	}
+
+	(*reply).Status = "OK"
+	(*reply).EarliestAt = backups[0].FinishedAt
+	(*reply).LatestAt = latest
+	return nil
+}
+
+func (r *RPCServer) RestoreDatabase(payload RestoreDatabasePayload, reply *CreateDatabaseResponse) error {
+
+	sourceUUID := payload.SourceUUID.String()
+	directoryUUID := payload.UUID.String()
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	ctx, done := r.cancellableDeployment(directoryUUID)
+	defer done()
+
+	tracker := r.trackDeployment(directoryUUID)
+
+	source, err := readTerraformVars(sourceUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	// The marker lets ResumeDatabase tell a half finished restore apart from
+	// a regular deployment that only needs Terraform applied again.
+	err = os.MkdirAll(directoryUUID, 0750)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(directoryUUID, restoreMarkerFile), []byte(sourceUUID), 0600)
+	}
+	if err != nil {
+		log.Println("Error preparing restore of deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	dbPort, exporterPort, err := r.createDatabase(ctx, payload.Name, payload.Password, source.DbUser, payload.Type, payload.Version, directoryUUID, payload.Limits, payload.StorageMB, bindAddress(payload.Connectivity))
+	if err == nil {
+		err = r.recoverDeployment(ctx, sourceUUID, source, directoryUUID, payload)
+	}
+
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
+
+	if err != nil {
+		r.discardDeployment(directoryUUID)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", directoryUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		log.Println("Error restoring deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	os.Remove(filepath.Join(directoryUUID, restoreMarkerFile))
+	tracker.succeed()
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err == nil {
+		publishMonitoringJob(rabbit.CreateAction, directoryUUID, payload.Type, vars, "")
+	}
+
+	(*reply).Status = "CREATED"
+	(*reply).NodeIP = app.MyIP
+	(*reply).NodePort = dbPort
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(directoryUUID))
+	return nil
+}
+
+// recoverDeployment replaces the data directory of a freshly created
+// deployment with a base backup of the source and replays archived WAL up to
+// the target time.
+func (r *RPCServer) recoverDeployment(ctx context.Context, sourceUUID string, source *TerraformVars, directoryUUID string, payload RestoreDatabasePayload) error {
+
+	target, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return newDeploymentError(StepPrepare, CategoryInternal, err)
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryDocker, err)
+	}
+	defer dockerClient.Close()
+
+	_, err = flushWalArchive(ctx, source, sourceUUID)
+	if err != nil {
+		log.Println("Error flushing WAL archive of source, recovering from what is archived:", err)
+	}
+
+	backups, err := listBaseBackups(sourceUUID)
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryStorage, err)
+	}
+
+	var backup *baseBackup
+	for i := range backups {
+		if !backups[i].FinishedAt.After(payload.TargetTime) {
+			backup = &backups[i]
+		}
+	}
+	if backup == nil {
+		return newDeploymentError(StepRecovery, CategoryDatabase, errors.New("no base backup finished before the target time"))
+	}
+
+	for _, containerName := range []string{target.ExporterContainerName, target.DbContainerName} {
+		err = dockerClient.ContainerStop(ctx, containerName, container.StopOptions{})
+		if err != nil {
+			return newDeploymentError(StepRecovery, CategoryDocker, err)
+		}
+	}
+
+	pgData := filepath.Join(target.DataPath, filepath.Base(containerPgDataDir))
+	restoreWal := filepath.Join(target.DataPath, restoreWalDir)
+
+	err = prepareRecovery(*backup, walArchivePath(sourceUUID), pgData, restoreWal, target.WalArchivePath)
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryStorage, err)
+	}
+
+	err = writeRecoverySettings(pgData, []string{
+		fmt.Sprintf("restore_command = 'cp %s/%s/%%f %%p'", containerDataDir, restoreWalDir),
+		fmt.Sprintf("recovery_target_time = '%s'", payload.TargetTime.UTC().Format("2006-01-02 15:04:05.999999-07")),
+		"recovery_target_action = 'promote'",
+	})
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryStorage, err)
+	}
+
+	err = dockerClient.ContainerStart(ctx, target.DbContainerName, container.StartOptions{})
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryDocker, err)
+	}
+
+	err = waitForRecovery(ctx, dockerClient, target.DbContainerName, source.DbUser)
+	if errors.Is(err, errRecoveryTargetNotReached) {
+		// Nothing was committed after the target, so the end of the archive
+		// is the state the database was in at that time.
+		err = writeRecoverySettings(pgData, []string{"recovery_target_time = ''"})
+		if err != nil {
+			return newDeploymentError(StepRecovery, CategoryStorage, err)
+		}
+
+		err = dockerClient.ContainerStart(ctx, target.DbContainerName, container.StartOptions{})
+		if err != nil {
+			return newDeploymentError(StepRecovery, CategoryDocker, err)
+		}
+
+		err = waitForRecovery(ctx, dockerClient, target.DbContainerName, source.DbUser)
+	}
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryDatabase, err)
+	}
+
+	for _, setting := range []string{"restore_command", "recovery_target_action", "recovery_target_time"} {
+		_, err = execSQL(ctx, dockerClient, target.DbContainerName, target.DbUser, "postgres", "ALTER SYSTEM RESET "+setting)
+		if err != nil {
+			return newDeploymentError(StepRecovery, CategoryDatabase, err)
+		}
+	}
+	os.RemoveAll(restoreWal)
+
+	if source.DbName != target.DbName {
+		recovered := *target
+		recovered.DbName = source.DbName
+
+		err = renameDatabase(ctx, dockerClient, &recovered, target.DbName)
+		if err != nil {
+			return newDeploymentError(StepRecovery, CategoryDatabase, err)
+		}
+	}
+
+	query := fmt.Sprintf("ALTER USER %s WITH PASSWORD %s", quoteIdentifier(target.DbUser), quoteLiteral(target.DbPassword))
+	_, err = execSQL(ctx, dockerClient, target.DbContainerName, target.DbUser, "postgres", query)
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryDatabase, err)
+	}
+
+	err = r.applyComputeLimits(ctx, directoryUUID, payload.Limits)
+	if err != nil {
+		return newDeploymentError(StepComputeLimits, CategoryDocker, err)
+	}
+
+	err = dockerClient.ContainerStart(ctx, target.ExporterContainerName, container.StartOptions{})
+	if err != nil {
+		return newDeploymentError(StepRecovery, CategoryDocker, err)
+	}
+
+	return nil
+}
+
+func prepareRecovery(backup baseBackup, sourceArchive string, pgData string, restoreWal string, targetArchive string) error {
+
+	err := os.RemoveAll(pgData)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(pgData, 0700)
+	if err != nil {
+		return err
+	}
+
+	backupFile, err := os.Open(backup.Path)
+	if err != nil {
+		return err
+	}
+	defer backupFile.Close()
+
+	err = extractTarGz(backupFile, pgData)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(restoreWal, 0755)
+	if err != nil {
+		return err
+	}
+
+	segments, err := os.ReadDir(sourceArchive)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		info, err := segment.Info()
+		if err != nil || strings.HasSuffix(segment.Name(), ".tmp") {
+			continue
+		}
+
+		if info.ModTime().Before(backup.StartedAt) && !strings.HasSuffix(segment.Name(), ".history") {
+			continue
+		}
+
+		err = copyFile(filepath.Join(sourceArchive, segment.Name()), filepath.Join(restoreWal, segment.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Segments archived by the throwaway cluster created with the deployment
+	// would clash with the names the recovered cluster archives under.
+	err = os.RemoveAll(targetArchive)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(targetArchive, 0750)
+	if err != nil {
+		return err
+	}
+
+	return os.Chown(targetArchive, postgresUID, postgresUID)
+}
+
+func writeRecoverySettings(pgData string, settings []string) error {
+	return writeServerSettings(pgData, "recovery.signal", settings)
+}
+
+// writeServerSettings appends settings for the next start of the cluster and
+// drops the signal file that starts it in recovery or as a standby.
+func writeServerSettings(pgData string, signalFile string, settings []string) error {
+
+	configFile, err := os.OpenFile(filepath.Join(pgData, "postgresql.auto.conf"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = configFile.WriteString("\n" + strings.Join(settings, "\n") + "\n")
+	configFile.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(pgData, signalFile), nil, 0600)
+}
+
+func containerLogContains(ctx context.Context, dockerClient *client.Client, containerName string, text string) bool {
+
+	logs, err := dockerClient.ContainerLogs(ctx, containerName, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       "20",
+	})
+	if err != nil {
+		return false
+	}
+	defer logs.Close()
+
+	output, err := io.ReadAll(logs)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(string(output), text)
+}
+
+func waitForRecovery(ctx context.Context, dockerClient *client.Client, containerName string, dbUser string) error {
+
+	deadline := time.Now().Add(recoveryTimeout)
+
+	for time.Now().Before(deadline) {
+		output, err := execSQL(ctx, dockerClient, containerName, dbUser, "postgres", "SELECT pg_is_in_recovery()")
+		if err == nil && strings.TrimSpace(output) == "f" {
+			return nil
+		}
+
+		inspect, inspectErr := dockerClient.ContainerInspect(ctx, containerName)
+		if inspectErr == nil && !inspect.State.Running {
+			if containerLogContains(ctx, dockerClient, containerName, recoveryTargetNotReachedLog) {
+				return errRecoveryTargetNotReached
+			}
+			return errors.New("database stopped during recovery")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+
+	return errors.New("recovery did not finish in time")
+}
+
+// flushWalArchive switches to a new WAL segment and waits until the finished
+// one is archived, so everything written up to now can be recovered. It
+// returns the time up to which the archive is complete.
+func flushWalArchive(ctx context.Context, vars *TerraformVars, directoryUUID string) (time.Time, error) {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer dockerClient.Close()
+
+	now := time.Now()
+
+	output, err := execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, "postgres", "SELECT pg_walfile_name(pg_switch_wal())")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	segment := filepath.Join(walArchivePath(directoryUUID), strings.TrimSpace(output))
+	deadline := time.Now().Add(walSwitchTimeout)
+
+	for {
+		if _, err := os.Stat(segment); err == nil {
+			return now, nil
+		}
+
+		if time.Now().After(deadline) {
+			return time.Time{}, errors.New("WAL segment was not archived in time")
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func latestArchivedWal(directoryUUID string) (time.Time, error) {
+
+	segments, err := os.ReadDir(walArchivePath(directoryUUID))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, segment := range segments {
+		info, err := segment.Info()
+		if err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+func walArchivePath(directoryUUID string) string {
+	return filepath.Join(backupsDir, directoryUUID, walArchiveDir)
+}
+
+func baseBackupPath(directoryUUID string) string {
+	return filepath.Join(backupsDir, directoryUUID, baseBackupsDir)
+}
+
+func createWalArchive(directoryUUID string) (string, error) {
+
+	archivePath, err := filepath.Abs(walArchivePath(directoryUUID))
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(archivePath, 0750)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Chown(archivePath, postgresUID, postgresUID)
+	if err != nil {
+		return "", err
+	}
+
+	return archivePath, nil
+}
+
+func archiveKey(directoryUUID string, dir string) string {
+	return path.Join(directoryUUID, dir) + "/"
+}
+
+func purgeArchive(directoryUUID string) error {
+
+	err := os.RemoveAll(walArchivePath(directoryUUID))
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(baseBackupPath(directoryUUID))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	err = backupstore.Expire(ctx, BackupStore, archiveKey(directoryUUID, walArchiveDir), time.Now())
+	if err != nil {
+		return err
+	}
+
+	return backupstore.Expire(ctx, BackupStore, archiveKey(directoryUUID, baseBackupsDir), time.Now())
+}
+
+// mirrorArchive copies WAL segments and base backups that are not in the
+// backup store yet, so the archive outlives the node's disk.
+func mirrorArchive(ctx context.Context, directoryUUID string) error {
+
+	for dir, localPath := range map[string]string{
+		walArchiveDir:  walArchivePath(directoryUUID),
+		baseBackupsDir: baseBackupPath(directoryUUID),
+	} {
+		prefix := archiveKey(directoryUUID, dir)
+
+		objects, err := BackupStore.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+
+		stored := make(map[string]bool, len(objects))
+		for _, object := range objects {
+			stored[object.Key] = true
+		}
+
+		entries, err := os.ReadDir(localPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") || stored[prefix+entry.Name()] {
+				continue
+			}
+
+			file, err := os.Open(filepath.Join(localPath, entry.Name()))
+			if err != nil {
+				return err
+			}
+
+			_, err = backupstore.Upload(ctx, BackupStore, prefix+entry.Name(), file)
+			file.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func deleteArchived(ctx context.Context, key string) error {
+	err := BackupStore.Delete(ctx, key)
+	if errors.Is(err, backupstore.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func listBaseBackups(directoryUUID string) ([]baseBackup, error) {
+
+	entries, err := os.ReadDir(baseBackupPath(directoryUUID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var backups []baseBackup
+	for _, entry := range entries {
+		startedAt, err := strconv.ParseInt(strings.TrimSuffix(entry.Name(), baseBackupSuffix), 10, 64)
+		if err != nil || !strings.HasSuffix(entry.Name(), baseBackupSuffix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, baseBackup{
+			Path:       filepath.Join(baseBackupPath(directoryUUID), entry.Name()),
+			StartedAt:  time.Unix(startedAt, 0),
+			FinishedAt: info.ModTime(),
+		})
+	}
+
+	slices.SortFunc(backups, func(a, b baseBackup) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return backups, nil
+}
+
+func takeBaseBackup(ctx context.Context, dockerClient *client.Client, vars *TerraformVars, directoryUUID string) error {
+
+	err := os.MkdirAll(baseBackupPath(directoryUUID), 0750)
+	if err != nil {
+		return err
+	}
+
+	startedAt := time.Now()
+	path := filepath.Join(baseBackupPath(directoryUUID), strconv.FormatInt(startedAt.Unix(), 10)+baseBackupSuffix)
+	tempPath := path + ".tmp"
+
+	backupFile, err := os.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempPath)
+
+	err = execToWriter(ctx, dockerClient, vars.DbContainerName, []string{
+		"pg_basebackup", "-U", vars.DbUser, "-D", "-", "-Ft", "-z", "-X", "none", "-c", "fast",
+	}, backupFile)
+	if err == nil {
+		err = backupFile.Sync()
+	}
+	backupFile.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
+
+// pruneArchive drops base backups beyond the retained count and the WAL
+// segments that were archived before the oldest remaining one started.
+func pruneArchive(directoryUUID string) error {
+
+	backups, err := listBaseBackups(directoryUUID)
+	if err != nil || len(backups) == 0 {
+		return err
+	}
+
+	if len(backups) > baseBackupsRetained {
+		for _, backup := range backups[:len(backups)-baseBackupsRetained] {
+			err = os.Remove(backup.Path)
+			if err == nil {
+				err = deleteArchived(context.Background(), archiveKey(directoryUUID, baseBackupsDir)+filepath.Base(backup.Path))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		backups = backups[len(backups)-baseBackupsRetained:]
+	}
+
+	segments, err := os.ReadDir(walArchivePath(directoryUUID))
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if strings.HasSuffix(segment.Name(), ".history") {
+			continue
+		}
+
+		info, err := segment.Info()
+		if err != nil || !info.ModTime().Before(backups[0].StartedAt) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(walArchivePath(directoryUUID), segment.Name()))
+		if err == nil {
+			err = deleteArchived(context.Background(), archiveKey(directoryUUID, walArchiveDir)+segment.Name())
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RPCServer) watchArchives() {
+
+	for {
+		time.Sleep(archiveCheckInterval)
+
+		entries, err := os.ReadDir(volumesDir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+
+			directoryUUID := entry.Name()
+
+			vars, err := readTerraformVars(directoryUUID)
+			if err != nil || vars.WalArchivePath == "" {
+				continue
+			}
+
+			if _, err := os.Stat(filepath.Join(directoryUUID, restoreMarkerFile)); err == nil {
+				continue
+			}
+
+			if replicaPrimary(directoryUUID) != "" {
+				continue
+			}
+
+			backups, err := listBaseBackups(directoryUUID)
+			if err != nil {
+				continue
+			}
+
+			if len(backups) == 0 || time.Since(backups[len(backups)-1].StartedAt) >= baseBackupInterval {
+				r.baseBackup(vars, directoryUUID)
+			}
+
+			err = mirrorArchive(context.Background(), directoryUUID)
+			if err != nil {
+				log.Println("Error mirroring WAL archive of deployment", directoryUUID, err)
+			}
+
+			err = pruneArchive(directoryUUID)
+			if err != nil {
+				log.Println("Error pruning WAL archive of deployment", directoryUUID, err)
+			}
+		}
+	}
+}
+
+func (r *RPCServer) baseBackup(vars *TerraformVars, directoryUUID string) {
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		return
+	}
+	defer dockerClient.Close()
+
+	ctx := context.Background()
+
+	inspect, err := dockerClient.ContainerInspect(ctx, vars.DbContainerName)
+	if err != nil || !inspect.State.Running {
+		return
+	}
+
+	err = takeBaseBackup(ctx, dockerClient, vars, directoryUUID)
+	if err != nil {
+		log.Println("Error taking base backup of deployment", directoryUUID, err)
+	}
+}
+
+func extractTarGz(archive io.Reader, destination string) error {
+
+	gzipReader, err := gzip.NewReader(archive)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(destination, header.Name)
+		if !strings.HasPrefix(path, filepath.Clean(destination)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0700)
+		case tar.TypeReg:
+			err = extractFile(tarReader, path, os.FileMode(header.Mode).Perm())
+		case tar.TypeSymlink:
+			err = os.Symlink(header.Linkname, path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractFile(reader io.Reader, path string, mode os.FileMode) error {
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
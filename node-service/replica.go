@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"node-service/rabbit"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	replicaMarkerFile    = "replica.of"
+	replicationHbaEntry  = "host replication all all scram-sha-256"
+	replicaStreamTimeout = 10 * time.Minute
+)
+
+type CreateReplicaPayload struct {
+	PrimaryUUID uuid.UUID
+	UUID        uuid.UUID
+	Type        string
+	Version     string
+	Limits      ComputeLimits
+	StorageMB   int64
+}
+
+// CreateReplica provisions a hot standby of a deployment on this node. It gets
+// its own ports and exporter and streams WAL from the primary through a
+// replication slot, so the primary keeps what the replica hasn't replayed yet.
+func (r *RPCServer) CreateReplica(payload CreateReplicaPayload, reply *CreateDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+	primaryUUID := payload.PrimaryUUID.String()
+
+	primary, err := readTerraformVars(primaryUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for primary:", err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	ctx, done := r.cancellableDeployment(directoryUUID)
+	defer done()
+
+	tracker := r.trackDeployment(directoryUUID)
+
+	dbPort, exporterPort, err := r.createDatabase(ctx, primary.DbName, primary.DbPassword, primary.DbUser, payload.Type, payload.Version, directoryUUID, payload.Limits, payload.StorageMB, primary.DbBindIP)
+
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
+
+	var vars *TerraformVars
+	if err == nil {
+		vars, err = readTerraformVars(directoryUUID)
+		if err != nil {
+			err = newDeploymentError(StepPrepare, CategoryInternal, err)
+		}
+	}
+
+	if err == nil {
+		err = startStandby(ctx, primaryUUID, primary, directoryUUID, vars)
+	}
+
+	if err != nil {
+		r.discardDeployment(directoryUUID)
+		dropReplication(primaryUUID, directoryUUID)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", directoryUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		log.Println("Error creating replica:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	tracker.succeed()
+
+	publishMonitoringJob(rabbit.CreateAction, directoryUUID, payload.Type, vars, "")
+
+	(*reply).Status = "CREATED"
+	(*reply).NodeIP = app.MyIP
+	(*reply).NodePort = dbPort
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(directoryUUID))
+	return nil
+}
+
+// startStandby replaces the data directory of a freshly created deployment
+// with a base backup of the primary and starts it as a streaming standby.
+func startStandby(ctx context.Context, primaryUUID string, primary *TerraformVars, directoryUUID string, replica *TerraformVars) error {
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryDocker, err)
+	}
+	defer dockerClient.Close()
+
+	err = os.WriteFile(filepath.Join(directoryUUID, replicaMarkerFile), []byte(primaryUUID), 0600)
+	if err != nil {
+		return newDeploymentError(StepPrepare, CategoryInternal, err)
+	}
+
+	password := make([]byte, 16)
+	rand.Read(password)
+
+	slot := replicationSlotName(directoryUUID)
+
+	err = prepareReplication(ctx, dockerClient, primary, slot, hex.EncodeToString(password))
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryDatabase, err)
+	}
+
+	for _, containerName := range []string{replica.ExporterContainerName, replica.DbContainerName} {
+		err = dockerClient.ContainerStop(ctx, containerName, container.StopOptions{})
+		if err != nil {
+			return newDeploymentError(StepReplication, CategoryDocker, err)
+		}
+	}
+
+	pgData := filepath.Join(replica.DataPath, filepath.Base(containerPgDataDir))
+
+	err = os.RemoveAll(pgData)
+	if err == nil {
+		err = os.MkdirAll(pgData, 0700)
+	}
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryStorage, err)
+	}
+
+	err = copyBaseBackup(ctx, dockerClient, primary, pgData)
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryDatabase, err)
+	}
+
+	conninfo := fmt.Sprintf("host=%s port=5432 user=%s password=%s application_name=%s", primary.DbContainerName, slot, hex.EncodeToString(password), directoryUUID)
+
+	err = writeServerSettings(pgData, "standby.signal", []string{
+		fmt.Sprintf("primary_conninfo = '%s'", conninfo),
+		fmt.Sprintf("primary_slot_name = '%s'", slot),
+	})
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryStorage, err)
+	}
+
+	err = dockerClient.ContainerStart(ctx, replica.DbContainerName, container.StartOptions{})
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryDocker, err)
+	}
+
+	err = waitForStreaming(ctx, dockerClient, replica.DbContainerName, replica.DbUser)
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryDatabase, err)
+	}
+
+	err = dockerClient.ContainerStart(ctx, replica.ExporterContainerName, container.StartOptions{})
+	if err != nil {
+		return newDeploymentError(StepReplication, CategoryDocker, err)
+	}
+
+	return nil
+}
+
+// prepareReplication lets the replica connect to the primary with its own
+// role, and reserves WAL for it from now on so the base backup can be taken
+// without archiving.
+func prepareReplication(ctx context.Context, dockerClient *client.Client, primary *TerraformVars, slot string, password string) error {
+
+	_, err := execInContainer(ctx, dockerClient, primary.DbContainerName, []string{
+		"sh", "-c", fmt.Sprintf("grep -qx '%[1]s' \"$PGDATA/pg_hba.conf\" || echo '%[1]s' >> \"$PGDATA/pg_hba.conf\"", replicationHbaEntry),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, query := range []string{
+		"SELECT pg_reload_conf()",
+		fmt.Sprintf("CREATE ROLE \"%s\" WITH REPLICATION LOGIN PASSWORD '%s'", slot, password),
+		fmt.Sprintf("SELECT pg_create_physical_replication_slot('%s', true)", slot),
+	} {
+		_, err = execSQL(ctx, dockerClient, primary.DbContainerName, primary.DbUser, "postgres", query)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyBaseBackup(ctx context.Context, dockerClient *client.Client, primary *TerraformVars, pgData string) error {
+
+	reader, writer := io.Pipe()
+	result := make(chan error, 1)
+
+	go func() {
+		err := execToWriter(ctx, dockerClient, primary.DbContainerName, []string{
+			"pg_basebackup", "-U", primary.DbUser, "-D", "-", "-Ft", "-z", "-X", "fetch", "-c", "fast",
+		}, writer)
+		writer.CloseWithError(err)
+		result <- err
+	}()
+
+	err := extractTarGz(reader, pgData)
+	if err == nil {
+		_, err = io.Copy(io.Discard, reader)
+	}
+	reader.CloseWithError(err)
+
+	backupErr := <-result
+	if err != nil {
+		return err
+	}
+
+	return backupErr
+}
+
+func waitForStreaming(ctx context.Context, dockerClient *client.Client, containerName string, dbUser string) error {
+
+	deadline := time.Now().Add(replicaStreamTimeout)
+
+	for time.Now().Before(deadline) {
+		output, err := execSQL(ctx, dockerClient, containerName, dbUser, "postgres", "SELECT status FROM pg_stat_wal_receiver")
+		if err == nil && strings.TrimSpace(output) == "streaming" {
+			return nil
+		}
+
+		inspect, inspectErr := dockerClient.ContainerInspect(ctx, containerName)
+		if inspectErr == nil && !inspect.State.Running {
+			return errors.New("replica stopped before it started streaming")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+
+	return errors.New("replica did not start streaming in time")
+}
+
+// replicaPrimary returns the deployment a replica streams from, or an empty
+// string for a deployment that isn't a replica.
+func replicaPrimary(directoryUUID string) string {
+
+	data, err := os.ReadFile(filepath.Join(directoryUUID, replicaMarkerFile))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// dropReplication removes the slot and role of a replica from its primary, a
+// slot left behind would keep WAL on the primary forever.
+func dropReplication(primaryUUID string, directoryUUID string) {
+
+	primary, err := readTerraformVars(primaryUUID)
+	if err != nil {
+		return
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		return
+	}
+	defer dockerClient.Close()
+
+	slot := replicationSlotName(directoryUUID)
+
+	for _, query := range []string{
+		fmt.Sprintf("SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = '%s'", slot),
+		fmt.Sprintf("DROP ROLE IF EXISTS \"%s\"", slot),
+	} {
+		_, err = execSQL(context.Background(), dockerClient, primary.DbContainerName, primary.DbUser, "postgres", query)
+		if err != nil {
+			log.Println("Error removing replication of", directoryUUID, "from primary", primaryUUID, err)
+		}
+	}
+}
+
+func replicationSlotName(directoryUUID string) string {
+	return "replica_" + strings.ReplaceAll(directoryUUID, "-", "_")
+}
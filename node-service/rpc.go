@@ -1,11 +1,10 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -17,9 +16,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
 	"github.com/google/uuid"
 )
 
@@ -37,51 +35,85 @@ type SendMessagePayload struct {
 }
 
 type CreateDatabasePayload struct {
-	Name     string
-	Type     string
-	Version  string
-	Password string
-	User     string
-	UUID     uuid.UUID
+	Name         string
+	Type         string
+	Version      string
+	Password     string
+	User         string
+	UUID         uuid.UUID
+	Limits       ComputeLimits
+	StorageMB    int64
+	Connectivity string
 }
 
 type CreateDatabaseResponse struct {
-	Status   string
-	NodeIP   string
-	NodePort string
+	Status     string
+	NodeIP     string
+	NodePort   string
+	VolumePath string
+	Error      string
 }
 
-type StatusLogsPair struct {
-	Log    string
+type DeleteDatabasePayload struct {
+	UUID         uuid.UUID
+	Type         string
+	GrafanaUID   string
+	RetainVolume bool
+}
+
+type DeleteDatabaseResponse struct {
 	Status string
 }
 
-var statusLogs = []StatusLogsPair{
-	{
-		Log:    "CREATE DATABASE",
-		Status: fmt.Sprintf("%s:%s", "Database created successfully...", "70%"),
-	},
-	{
-		Log:    "Starting PostgreSQL",
-		Status: fmt.Sprintf("%s:%s", "Deployment finished successfully...", "80%"),
-	},
-	{
-		Log:    "database system is ready to accept connections",
-		Status: fmt.Sprintf("%s:%s", "Deployment finished successfully...", "100%"),
-	},
+type ResumeDatabasePayload struct {
+	UUID   uuid.UUID
+	Type   string
+	Limits ComputeLimits
+}
+
+type CancelDeploymentPayload struct {
+	UUID uuid.UUID
+}
+
+type CancelDeploymentResponse struct {
+	Status string
 }
 
 func (r *RPCServer) CreateDatabase(payload CreateDatabasePayload, reply *CreateDatabaseResponse) error {
 
-	go r.trackDeploymentStatus(payload.UUID.String())
+	unlock := r.lockDeployment(payload.UUID.String())
+	defer unlock()
+
+	ctx, done := r.cancellableDeployment(payload.UUID.String())
+	defer done()
+
+	tracker := r.trackDeployment(payload.UUID.String())
 
-	dbPort, exporterPort, err := r.createDatabase(payload.Name, payload.Password, payload.User, payload.Type, payload.Version, payload.UUID.String())
+	dbPort, exporterPort, err := r.createDatabase(ctx, payload.Name, payload.Password, payload.User, payload.Type, payload.Version, payload.UUID.String(), payload.Limits, payload.StorageMB, bindAddress(payload.Connectivity))
 	if err != nil {
+		dbPortNumber, _ := strconv.Atoi(dbPort)
+		exporterPortNumber, _ := strconv.Atoi(exporterPort)
+
+		r.discardDeployment(payload.UUID.String())
+		r.releasePorts(dbPortNumber, exporterPortNumber)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", payload.UUID.String(), "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		tracker.fail(err)
 		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
 		return nil
 	}
 
+	tracker.succeed()
+
 	RabbitPayload := rabbit.Job{
+		Action:        rabbit.CreateAction,
 		DashboardName: payload.UUID.String(),
 		DbType:        payload.Type,
 		NodeIP:        utils.URL.MyIP,
@@ -98,103 +130,249 @@ func (r *RPCServer) CreateDatabase(payload CreateDatabasePayload, reply *CreateD
 	(*reply).Status = "CREATED"
 	(*reply).NodeIP = app.MyIP
 	(*reply).NodePort = dbPort
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(payload.UUID.String()))
 
-	r.portMtx.Lock()
-	defer r.portMtx.Unlock()
-
-	portNumber, _ := strconv.Atoi(dbPort)
-	delete(r.portReserved, portNumber)
-
-	portNumber, _ = strconv.Atoi(exporterPort)
-	delete(r.portReserved, portNumber)
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
 
 	return nil
 }
 
-func (r *RPCServer) trackDeploymentStatus(deploymentUUID string) {
+func (r *RPCServer) ResumeDatabase(payload ResumeDatabasePayload, reply *CreateDatabaseResponse) error {
 
-	statusCmd := r.redisClient.Set(deploymentUUID, fmt.Sprintf("%s:%s", "Preparing terraform file for deployment...", "20%"), 0)
-	if err := statusCmd.Err(); err != nil {
-		log.Println(err)
-	}
+	directoryUUID := payload.UUID.String()
 
-	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	vars, err := readTerraformVars(directoryUUID)
 	if err != nil {
-		log.Println("Can't create docker client")
-		return
+		log.Println("No deployment to resume for", directoryUUID)
+		(*reply).Status = "NOT_FOUND"
+		return nil
 	}
 
-	ctx := context.Background()
+	ctx, done := r.cancellableDeployment(directoryUUID)
+	defer done()
 
-	for {
+	// A deployment that already succeeded has its monitoring target, only the
+	// config service missed the reply.
+	monitored := r.deploymentState(directoryUUID) == DeploymentSucceeded
 
-		_, err = dockerClient.ContainerInspect(ctx, deploymentUUID)
-		if err != nil && client.IsErrNotFound(err) {
-			r.redisClient.Set(deploymentUUID, fmt.Sprintf("%s:%s", "Preparing terraform file for deployment...", "20%"), 0)
-			continue
-		}
+	tracker := r.trackDeployment(directoryUUID)
 
+	if _, err := os.Stat(filepath.Join(directoryUUID, restoreMarkerFile)); err == nil {
+		err = newDeploymentError(StepRecovery, CategoryInternal, errors.New("restore was interrupted and can't be resumed"))
+		tracker.fail(err)
+		r.discardDeployment(directoryUUID)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	err = r.terraformApply(ctx, directoryUUID)
+	if err != nil {
+		log.Println("Failed to apply Terraform:", err)
+		err = newDeploymentError(StepTerraformApply, CategoryTerraform, err)
+	}
+
+	if err == nil {
+		err = r.applyComputeLimits(ctx, directoryUUID, payload.Limits)
 		if err != nil {
-			log.Println("Error inspecting container")
-			return
+			log.Println("Failed to apply compute limits:", err)
+			err = newDeploymentError(StepComputeLimits, CategoryDocker, err)
 		}
+	}
 
-		r.redisClient.Set(deploymentUUID, fmt.Sprintf("%s:%s", "Deployment of container started...", "40%"), 0)
-		break
+	if err != nil {
+		r.discardDeployment(directoryUUID)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", directoryUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
 	}
 
-	r.redisClient.Set(deploymentUUID, fmt.Sprintf("%s:%s", "Preparing database...", "60%"), 0)
-	lastPercentage := 0
+	tracker.succeed()
+	if !monitored {
+		publishMonitoringJob(rabbit.CreateAction, directoryUUID, payload.Type, vars, "")
+	}
 
-	for {
+	(*reply).Status = "CREATED"
+	(*reply).NodeIP = app.MyIP
+	(*reply).NodePort = strconv.Itoa(vars.DbPort)
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(directoryUUID))
+	return nil
+}
 
-		_, err = dockerClient.ContainerInspect(ctx, deploymentUUID)
-		if err != nil {
-			log.Println("Error inspecting container")
-			return
-		}
+func (r *RPCServer) CancelDeployment(payload CancelDeploymentPayload, reply *CancelDeploymentResponse) error {
 
-		out, err := dockerClient.ContainerLogs(ctx, deploymentUUID, container.LogsOptions{
-			ShowStdout: true,
-			ShowStderr: true,
-			Follow:     false,
-			Tail:       "10",
-		})
-		if err != nil {
-			log.Printf("Error getting container logs: %v", err)
-		}
-		defer out.Close()
+	directoryUUID := payload.UUID.String()
+
+	r.deployMtx.Lock()
+	cancel, exists := r.cancels[directoryUUID]
+	r.deployMtx.Unlock()
+
+	if !exists {
+		(*reply).Status = "NOT_FOUND"
+		return nil
+	}
+
+	cancel()
+
+	unlock := r.lockDeployment(directoryUUID)
+	unlock()
+
+	(*reply).Status = r.deploymentState(directoryUUID)
+	return nil
+}
+
+func (r *RPCServer) cancellableDeployment(directoryUUID string) (context.Context, func()) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r.deployMtx.Lock()
+	r.cancels[directoryUUID] = cancel
+	r.deployMtx.Unlock()
+
+	return ctx, func() {
+		r.deployMtx.Lock()
+		delete(r.cancels, directoryUUID)
+		r.deployMtx.Unlock()
+		cancel()
+	}
+}
+
+type deploymentLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+// lockDeployment serializes work on a deployment. The lock is dropped from
+// the map once nobody holds or waits for it, so it doesn't grow with every
+// deployment the node has seen.
+func (r *RPCServer) lockDeployment(directoryUUID string) func() {
+
+	r.deployMtx.Lock()
+	lock, exists := r.deployLocks[directoryUUID]
+	if !exists {
+		lock = &deploymentLock{}
+		r.deployLocks[directoryUUID] = lock
+	}
+	lock.users++
+	r.deployMtx.Unlock()
 
-		var buffer bytes.Buffer
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
 
-		scanner := bufio.NewScanner(out)
-		for scanner.Scan() {
-			buffer.WriteString(scanner.Text())
+		r.deployMtx.Lock()
+		lock.users--
+		if lock.users == 0 {
+			delete(r.deployLocks, directoryUUID)
 		}
+		r.deployMtx.Unlock()
+	}
+}
+
+func (r *RPCServer) DeleteDatabase(payload DeleteDatabasePayload, reply *DeleteDatabaseResponse) error {
+
+	directoryUUID := payload.UUID.String()
+	primaryUUID := replicaPrimary(directoryUUID)
+
+	unlock := r.lockDeployment(directoryUUID)
+	defer unlock()
+
+	vars, err := r.destroyDeployment(directoryUUID, payload.RetainVolume)
+	if err != nil {
+		log.Println("Failed to destroy deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	if primaryUUID != "" {
+		dropReplication(primaryUUID, directoryUUID)
+	}
+
+	r.redisClient.Del(directoryUUID)
+	publishMonitoringJob(rabbit.DeleteAction, directoryUUID, payload.Type, vars, payload.GrafanaUID)
+
+	(*reply).Status = "DELETED"
+	return nil
+}
 
-		logsDump := buffer.String()
+func (r *RPCServer) destroyDeployment(directoryUUID string, retainVolume bool) (*TerraformVars, error) {
 
-		for _, sl := range statusLogs {
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return nil, err
+	}
 
-			if strings.Contains(logsDump, sl.Log) {
-				currPercentageSplit := strings.Split(sl.Status, ":")[1]
-				currPercentageString := strings.Split(currPercentageSplit, "%")[0]
-				currPercentage, _ := strconv.Atoi(currPercentageString)
+	err = r.terraformDestroy(directoryUUID)
+	if err != nil {
+		return nil, err
+	}
 
-				if currPercentage <= lastPercentage {
-					continue
-				}
-				lastPercentage = currPercentage
+	r.releasePorts(vars.DbPort, vars.ExporterPort)
 
-				r.redisClient.Set(deploymentUUID, sl.Status, 0)
+	if retainVolume {
+		err = unmountVolume(directoryUUID)
+	} else {
+		err = purgeVolume(directoryUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-				if sl.Log == statusLogs[len(statusLogs)-1].Log {
-					log.Println("Deployment completed.")
-					return
-				}
-			}
+	if !retainVolume {
+		err = purgeArchive(directoryUUID)
+		if err != nil {
+			log.Println("Error removing WAL archive of deployment", directoryUUID, err)
 		}
 	}
+
+	err = os.RemoveAll(directoryUUID)
+	if err != nil {
+		log.Println("Error removing deployment directory")
+	}
+
+	return vars, nil
+}
+
+func publishMonitoringJob(action string, directoryUUID string, dbType string, vars *TerraformVars, grafanaUID string) {
+
+	RabbitPayload := rabbit.Job{
+		Action:        action,
+		DashboardName: directoryUUID,
+		DbType:        dbType,
+		NodeIP:        utils.URL.MyIP,
+		NodePort:      strconv.Itoa(vars.ExporterPort),
+		Datname:       vars.DbName,
+		GrafanaUID:    grafanaUID,
+	}
+	body, _ := json.Marshal(RabbitPayload)
+
+	err := Publisher.Push(body)
+	if err != nil {
+		log.Println("Error sending message to monitoring queue")
+	}
+}
+
+func (r *RPCServer) releasePorts(ports ...int) {
+	r.portMtx.Lock()
+	defer r.portMtx.Unlock()
+
+	for _, port := range ports {
+		delete(r.portReserved, port)
+	}
 }
 
 func (r *RPCServer) SendMessage(payload SendMessagePayload, reply *string) error {
@@ -255,45 +433,81 @@ func (r *RPCServer) processMessage(message string) {
 	}
 }
 
-func (r *RPCServer) createDatabase(dbName, dbPassword, dbUser, dbType, version, directoryUUID string) (string, string, error) {
+func (r *RPCServer) createDatabase(ctx context.Context, dbName, dbPassword, dbUser, dbType, version, directoryUUID string, limits ComputeLimits, storageMB int64, bindIP string) (string, string, error) {
 
 	scriptLocation := dbType + "/" + version + "/main.tf"
 
 	err := os.MkdirAll(directoryUUID, 0750)
 	if err != nil {
 		log.Println("Error creating directory for user uuid")
-		return "", "", err
+		return "", "", newDeploymentError(StepPrepare, CategoryInternal, err)
 	}
 
 	err = copyFile(scriptLocation, directoryUUID+"/main.tf")
 	if err != nil {
 		log.Println("Error when copying terraform file from source to user directory")
-		return "", "", err
+		return "", "", newDeploymentError(StepPrepare, CategoryInternal, err)
 	}
 
-	err = r.terraformInit(directoryUUID)
+	err = r.terraformInit(ctx, directoryUUID)
 	if err != nil {
 		log.Println("Failed to initialize Terraform:", err)
-		return "", "", err
+		return "", "", newDeploymentError(StepTerraformInit, CategoryTerraform, err)
+	}
+
+	dataPath, err := createStorage(directoryUUID, storageMB)
+	if err != nil {
+		log.Println("Error creating storage for deployment:", err)
+		return "", "", newDeploymentError(StepStorage, CategoryStorage, err)
+	}
+
+	walArchivePath, err := createWalArchive(directoryUUID)
+	if err != nil {
+		log.Println("Error creating WAL archive for deployment:", err)
+		return "", "", newDeploymentError(StepStorage, CategoryStorage, err)
 	}
 
 	dbPort := r.getAvailablePort()
 	exporterPort := r.getAvailablePort()
 
-	cmd := exec.Command("terraform", "apply", "-auto-approve",
-		"-var", fmt.Sprintf("db_name=%s", dbName),
-		"-var", fmt.Sprintf("db_password=%s", dbPassword),
-		"-var", fmt.Sprintf("db_user=%s", dbUser),
-		"-var", fmt.Sprintf("db_port=%v", dbPort),
-		"-var", fmt.Sprintf("db_container_name=%s", directoryUUID),
-		"-var", fmt.Sprintf("exporter_port=%v", exporterPort),
-		"-var", fmt.Sprintf("exporter_container_name=%s", directoryUUID+"exporter"),
-		"-var", fmt.Sprintf("node_ip=%s", utils.URL.MyIP))
-
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Dir = directoryUUID
-	return strconv.Itoa(dbPort), strconv.Itoa(exporterPort), cmd.Run()
+	vars := TerraformVars{
+		DbName:                dbName,
+		DbPassword:            dbPassword,
+		DbUser:                dbUser,
+		DbPort:                dbPort,
+		DbContainerName:       directoryUUID,
+		ExporterPort:          exporterPort,
+		ExporterContainerName: directoryUUID + "exporter",
+		NodeIP:                utils.URL.MyIP,
+		DataPath:              dataPath,
+		WalArchivePath:        walArchivePath,
+		DbBindIP:              bindIP,
+	}
+
+	if limits.CPU > 0 && limits.MemoryMB > 0 {
+		vars.CPU = limits.CPU
+		vars.MemoryMB = limits.MemoryMB
+	}
+
+	err = writeTerraformVars(directoryUUID, vars)
+	if err != nil {
+		log.Println("Error writing terraform variables for deployment")
+		return "", "", newDeploymentError(StepPrepare, CategoryInternal, err)
+	}
+
+	err = r.terraformApply(ctx, directoryUUID)
+	if err != nil {
+		log.Println("Failed to apply Terraform:", err)
+		return strconv.Itoa(dbPort), strconv.Itoa(exporterPort), newDeploymentError(StepTerraformApply, CategoryTerraform, err)
+	}
+
+	err = r.applyComputeLimits(ctx, directoryUUID, limits)
+	if err != nil {
+		log.Println("Failed to apply compute limits:", err)
+		return strconv.Itoa(dbPort), strconv.Itoa(exporterPort), newDeploymentError(StepComputeLimits, CategoryDocker, err)
+	}
+
+	return strconv.Itoa(dbPort), strconv.Itoa(exporterPort), nil
 }
 
 func copyFile(src, dst string) error {
@@ -323,14 +537,6 @@ func copyFile(src, dst string) error {
 	return nil
 }
 
-func (r *RPCServer) terraformInit(workingDir string) error {
-	cmd := exec.Command("terraform", "init")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Dir = workingDir
-	return cmd.Run()
-}
-
 func (r *RPCServer) getAvailablePort() int {
 	r.portMtx.Lock()
 	defer r.portMtx.Unlock()
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	volumesDir            = "volumes"
+	storageImageFile      = "storage.img"
+	storageMountDir       = "data"
+	storageFullPercentage = 95
+	storageFullMarkerFile = "storage.full"
+)
+
+type StoragePayload struct {
+	UUID      uuid.UUID
+	StorageMB int64
+}
+
+type StorageUsageResponse struct {
+	Status     string
+	UsedBytes  int64
+	TotalBytes int64
+}
+
+type GrowStorageResponse struct {
+	Status string
+}
+
+type PurgeVolumePayload struct {
+	UUID uuid.UUID
+}
+
+type PurgeVolumeResponse struct {
+	Status string
+}
+
+func (r *RPCServer) StorageUsage(payload StoragePayload, reply *StorageUsageResponse) error {
+
+	used, total, err := storageUsage(payload.UUID.String())
+	if err != nil {
+		log.Println("Error reading storage usage:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "OK"
+	(*reply).UsedBytes = used
+	(*reply).TotalBytes = total
+	return nil
+}
+
+func (r *RPCServer) GrowStorage(payload StoragePayload, reply *GrowStorageResponse) error {
+
+	err := growStorage(payload.UUID.String(), payload.StorageMB)
+	if err != nil {
+		log.Println("Error growing storage:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "GROWN"
+	return nil
+}
+
+func (r *RPCServer) PurgeVolume(payload PurgeVolumePayload, reply *PurgeVolumeResponse) error {
+
+	err := purgeVolume(payload.UUID.String())
+	if err != nil {
+		log.Println("Error purging volume:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "PURGED"
+	return nil
+}
+
+func volumePath(directoryUUID string) string {
+	return filepath.Join(volumesDir, directoryUUID)
+}
+
+func createStorage(directoryUUID string, storageMB int64) (string, error) {
+
+	mountPoint, err := filepath.Abs(filepath.Join(volumePath(directoryUUID), storageMountDir))
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(mountPoint, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	if storageMB <= 0 {
+		return mountPoint, nil
+	}
+
+	imagePath := filepath.Join(volumePath(directoryUUID), storageImageFile)
+
+	imageFile, err := os.Create(imagePath)
+	if err != nil {
+		return "", err
+	}
+
+	err = imageFile.Truncate(storageMB * 1024 * 1024)
+	imageFile.Close()
+	if err != nil {
+		return "", err
+	}
+
+	err = runCommand("mkfs.ext4", "-q", "-F", imagePath)
+	if err != nil {
+		return "", err
+	}
+
+	err = runCommand("mount", "-o", "loop", imagePath, mountPoint)
+	if err != nil {
+		return "", err
+	}
+
+	return mountPoint, nil
+}
+
+func growStorage(directoryUUID string, storageMB int64) error {
+
+	imagePath := filepath.Join(volumePath(directoryUUID), storageImageFile)
+
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return err
+	}
+
+	newSize := storageMB * 1024 * 1024
+	if newSize <= info.Size() {
+		return errors.New("storage can only be grown")
+	}
+
+	err = os.Truncate(imagePath, newSize)
+	if err != nil {
+		return err
+	}
+
+	loopDevice, err := findLoopDevice(imagePath)
+	if err != nil {
+		return err
+	}
+
+	err = runCommand("losetup", "-c", loopDevice)
+	if err != nil {
+		return err
+	}
+
+	return runCommand("resize2fs", loopDevice)
+}
+
+func unmountVolume(directoryUUID string) error {
+
+	mountPoint := filepath.Join(volumePath(directoryUUID), storageMountDir)
+	if !isMounted(mountPoint) {
+		return nil
+	}
+
+	return runCommand("umount", mountPoint)
+}
+
+func purgeVolume(directoryUUID string) error {
+
+	err := unmountVolume(directoryUUID)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(volumePath(directoryUUID))
+}
+
+func mountVolumes() {
+
+	entries, err := os.ReadDir(volumesDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+
+		imagePath := filepath.Join(volumesDir, entry.Name(), storageImageFile)
+		mountPoint := filepath.Join(volumesDir, entry.Name(), storageMountDir)
+
+		if _, err := os.Stat(imagePath); err != nil || isMounted(mountPoint) {
+			continue
+		}
+
+		err = runCommand("mount", "-o", "loop", imagePath, mountPoint)
+		if err != nil {
+			log.Println("Error mounting volume", entry.Name(), err)
+		}
+	}
+}
+
+func isMounted(mountPoint string) bool {
+	return exec.Command("mountpoint", "-q", mountPoint).Run() == nil
+}
+
+func storageUsage(directoryUUID string) (int64, int64, error) {
+
+	var stat syscall.Statfs_t
+
+	err := syscall.Statfs(filepath.Join(volumePath(directoryUUID), storageMountDir), &stat)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	total := int64(stat.Blocks) * stat.Bsize
+	free := int64(stat.Bavail) * stat.Bsize
+
+	return total - free, total, nil
+}
+
+func findLoopDevice(imagePath string) (string, error) {
+
+	output, err := exec.Command("losetup", "-j", imagePath).Output()
+	if err != nil {
+		return "", err
+	}
+
+	device, _, found := strings.Cut(string(output), ":")
+	if !found {
+		return "", fmt.Errorf("no loop device attached to %s", imagePath)
+	}
+
+	return device, nil
+}
+
+func runCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// watchStorage makes deployments read only once their volume is nearly full
+// and writable again after it was grown. A marker file remembers which ones it
+// locked, so a restart neither forgets them nor unlocks deployments made read
+// only for other reasons.
+func (r *RPCServer) watchStorage() {
+
+	for {
+		time.Sleep(time.Minute)
+
+		entries, err := os.ReadDir(volumesDir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+
+			directoryUUID := entry.Name()
+			if _, err := os.Stat(filepath.Join(directoryUUID, terraformVarsFile)); err != nil {
+				continue
+			}
+
+			if _, err := os.Stat(filepath.Join(volumePath(directoryUUID), storageImageFile)); err != nil {
+				continue
+			}
+
+			used, total, err := storageUsage(directoryUUID)
+			if err != nil || total == 0 {
+				continue
+			}
+
+			full := used*100/total >= storageFullPercentage
+			if full == storageFull(directoryUUID) {
+				continue
+			}
+
+			err = r.setReadOnly(directoryUUID, full)
+			if err != nil {
+				log.Println("Error changing read only mode for deployment", directoryUUID, err)
+				continue
+			}
+
+			marker := filepath.Join(directoryUUID, storageFullMarkerFile)
+			if full {
+				log.Println("Storage limit reached, deployment", directoryUUID, "is now read only")
+				err = os.WriteFile(marker, nil, 0600)
+			} else {
+				err = os.Remove(marker)
+			}
+			if err != nil {
+				log.Println("Error recording storage state for deployment", directoryUUID, err)
+			}
+		}
+	}
+}
+
+func storageFull(directoryUUID string) bool {
+	_, err := os.Stat(filepath.Join(directoryUUID, storageFullMarkerFile))
+	return err == nil
+}
+
+func (r *RPCServer) isReadOnly(directoryUUID string) (bool, error) {
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return false, err
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return false, err
+	}
+	defer dockerClient.Close()
+
+	output, err := execSQL(context.Background(), dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName, "SHOW default_transaction_read_only")
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(output) == "on", nil
+}
+
+func (r *RPCServer) setReadOnly(directoryUUID string, readOnly bool) error {
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	ctx := context.Background()
+
+	query := fmt.Sprintf("ALTER DATABASE %s SET default_transaction_read_only = %t", quoteIdentifier(vars.DbName), readOnly)
+	_, err = execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, "postgres", query)
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = %s AND pid <> pg_backend_pid()", quoteLiteral(vars.DbName))
+	_, err = execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, "postgres", query)
+	return err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	terraformVarsFile    = "terraform.tfvars.json"
+	terraformStopTimeout = 30 * time.Second
+)
+
+type TerraformVars struct {
+	DbName                string  `json:"db_name"`
+	DbPassword            string  `json:"db_password"`
+	DbUser                string  `json:"db_user"`
+	DbPort                int     `json:"db_port"`
+	DbContainerName       string  `json:"db_container_name"`
+	ExporterPort          int     `json:"exporter_port"`
+	ExporterContainerName string  `json:"exporter_container_name"`
+	NodeIP                string  `json:"node_ip"`
+	DataPath              string  `json:"data_path"`
+	DbBindIP              string  `json:"db_bind_ip,omitempty"`
+	WalArchivePath        string  `json:"wal_archive_path,omitempty"`
+	CPU                   float64 `json:"cpu,omitempty"`
+	MemoryMB              int64   `json:"memory_mb,omitempty"`
+}
+
+func writeTerraformVars(workingDir string, vars TerraformVars) error {
+
+	data, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(workingDir, terraformVarsFile), data, 0600)
+}
+
+func readTerraformVars(workingDir string) (*TerraformVars, error) {
+
+	data, err := os.ReadFile(filepath.Join(workingDir, terraformVarsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var vars TerraformVars
+	err = json.Unmarshal(data, &vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vars, nil
+}
+
+func (r *RPCServer) terraformInit(ctx context.Context, workingDir string) error {
+	return runTerraform(ctx, workingDir, "init")
+}
+
+func (r *RPCServer) terraformApply(ctx context.Context, workingDir string, args ...string) error {
+	return runTerraform(ctx, workingDir, append([]string{"apply", "-auto-approve", "-input=false"}, args...)...)
+}
+
+func (r *RPCServer) terraformDestroy(workingDir string) error {
+	return runTerraform(context.Background(), workingDir, "destroy", "-auto-approve", "-input=false")
+}
+
+func runTerraform(ctx context.Context, workingDir string, args ...string) error {
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "terraform", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	cmd.Dir = workingDir
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = terraformStopTimeout
+
+	err := cmd.Run()
+	if err != nil {
+		return &CommandError{Err: err, Output: stderrTail(stderr.String())}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+)
+
+const (
+	containerDataDir = "/var/lib/postgresql/data"
+	transferDumpFile = "transfer.dump"
+)
+
+func transferData(ctx context.Context, dockerClient *client.Client, source *TerraformVars, target *TerraformVars) error {
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	go func() {
+		writer.CloseWithError(dumpDatabase(ctx, dockerClient, source, writer))
+	}()
+
+	return restoreDatabase(ctx, dockerClient, target, reader)
+}
+
+func dumpDatabase(ctx context.Context, dockerClient *client.Client, source *TerraformVars, dump io.Writer) error {
+	return execToWriter(ctx, dockerClient, source.DbContainerName, []string{
+		"pg_dump", "-U", source.DbUser, "-d", source.DbName, "-Fc",
+	}, dump)
+}
+
+func restoreDatabase(ctx context.Context, dockerClient *client.Client, target *TerraformVars, dump io.Reader) error {
+
+	dumpPath := filepath.Join(target.DataPath, transferDumpFile)
+
+	dumpFile, err := os.Create(dumpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dumpPath)
+
+	_, err = io.Copy(dumpFile, dump)
+	dumpFile.Close()
+	if err != nil {
+		return err
+	}
+
+	err = waitForDatabase(ctx, dockerClient, target.DbContainerName, target.DbUser, target.DbName)
+	if err != nil {
+		return err
+	}
+
+	_, err = execInContainer(ctx, dockerClient, target.DbContainerName, []string{
+		"pg_restore", "-U", target.DbUser, "-d", target.DbName, "--no-owner", "--no-acl",
+		containerDataDir + "/" + transferDumpFile,
+	})
+	return err
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"log"
+	"node-service/rabbit"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+type UpgradeDatabasePayload struct {
+	UUID      uuid.UUID
+	NewUUID   uuid.UUID
+	Type      string
+	Version   string
+	Limits    ComputeLimits
+	StorageMB int64
+}
+
+type UpgradeDatabaseResponse struct {
+	Status     string
+	NodePort   string
+	VolumePath string
+}
+
+type FinishUpgradePayload struct {
+	UUID       uuid.UUID
+	NewUUID    uuid.UUID
+	Type       string
+	GrafanaUID string
+}
+
+type FinishUpgradeResponse struct {
+	Status string
+}
+
+type TemplatePayload struct {
+	Type    string
+	Version string
+}
+
+type TemplateResponse struct {
+	Status string
+}
+
+// TemplateExists reports whether this node has the terraform template of a
+// version, only those can be deployed on it.
+func (r *RPCServer) TemplateExists(payload TemplatePayload, reply *TemplateResponse) error {
+
+	_, err := os.Stat(filepath.Join(payload.Type, payload.Version, "main.tf"))
+	if err != nil {
+		(*reply).Status = "NOT_FOUND"
+		return nil
+	}
+
+	(*reply).Status = "OK"
+	return nil
+}
+
+func (r *RPCServer) UpgradeDatabase(payload UpgradeDatabasePayload, reply *UpgradeDatabaseResponse) error {
+
+	oldUUID := payload.UUID.String()
+	newUUID := payload.NewUUID.String()
+
+	source, err := readTerraformVars(oldUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	unlock := r.lockDeployment(newUUID)
+	defer unlock()
+
+	ctx, done := r.cancellableDeployment(newUUID)
+	defer done()
+
+	tracker := r.trackDeployment(newUUID)
+
+	dbPort, exporterPort, err := r.createDatabase(ctx, source.DbName, source.DbPassword, source.DbUser, payload.Type, payload.Version, newUUID, payload.Limits, payload.StorageMB, source.DbBindIP)
+
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
+
+	if err != nil {
+		r.discardDeployment(newUUID)
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", newUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		log.Println("Error provisioning upgraded deployment:", err)
+		tracker.fail(err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	target, err := readTerraformVars(newUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		tracker.fail(err)
+		r.discardDeployment(newUUID)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Println("Can't create docker client")
+		tracker.fail(err)
+		r.discardDeployment(newUUID)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	defer dockerClient.Close()
+
+	// Writes made while the dump is taken and restored would not make it to
+	// the new deployment, so the source is read only until it's stopped. It
+	// may already be, for a full volume, and then has to stay that way.
+	wasReadOnly, err := r.isReadOnly(oldUUID)
+	if err == nil && !wasReadOnly {
+		err = r.setReadOnly(oldUUID, true)
+	}
+	if err != nil {
+		log.Println("Error making the previous deployment read only:", err)
+		tracker.fail(newDeploymentError(StepDataTransfer, CategoryDatabase, err))
+		r.discardDeployment(newUUID)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	err = transferData(ctx, dockerClient, source, target)
+	if err != nil {
+		r.discardDeployment(newUUID)
+
+		if !wasReadOnly {
+			if err := r.setReadOnly(oldUUID, false); err != nil {
+				log.Println("Error making the previous deployment writable again:", err)
+			}
+		}
+
+		if ctx.Err() != nil {
+			log.Println("Deployment", newUUID, "cancelled")
+			tracker.cancel()
+			(*reply).Status = "CANCELLED"
+			return nil
+		}
+
+		log.Println("Error transferring data to upgraded deployment:", err)
+		tracker.fail(newDeploymentError(StepDataTransfer, CategoryDatabase, err))
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	tracker.succeed()
+
+	var stopReply PowerStateResponse
+	r.StopDatabase(PowerStatePayload{UUID: payload.UUID}, &stopReply)
+	if stopReply.Status != "STOPPED" {
+		log.Println("Error stopping the previous deployment after upgrade")
+	}
+
+	(*reply).Status = "UPGRADED"
+	(*reply).NodePort = dbPort
+	(*reply).VolumePath, _ = filepath.Abs(volumePath(newUUID))
+	return nil
+}
+
+func (r *RPCServer) ConfirmUpgrade(payload FinishUpgradePayload, reply *FinishUpgradeResponse) error {
+
+	oldUUID := payload.UUID.String()
+	newUUID := payload.NewUUID.String()
+
+	unlock := r.lockDeployment(oldUUID)
+	defer unlock()
+
+	source, err := r.destroyDeployment(oldUUID, false)
+	if err != nil {
+		log.Println("Failed to destroy the previous deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	target, err := readTerraformVars(newUUID)
+	if err != nil {
+		log.Println("Error reading terraform variables for deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	r.redisClient.Del(oldUUID)
+	publishMonitoringJob(rabbit.DeleteAction, oldUUID, payload.Type, source, payload.GrafanaUID)
+	publishMonitoringJob(rabbit.CreateAction, newUUID, payload.Type, target, "")
+
+	(*reply).Status = "CONFIRMED"
+	return nil
+}
+
+func (r *RPCServer) RollbackUpgrade(payload FinishUpgradePayload, reply *FinishUpgradeResponse) error {
+
+	unlock := r.lockDeployment(payload.NewUUID.String())
+	defer unlock()
+
+	_, err := r.destroyDeployment(payload.NewUUID.String(), false)
+	if err != nil {
+		log.Println("Failed to destroy the upgraded deployment:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+	r.redisClient.Del(payload.NewUUID.String())
+
+	var startReply PowerStateResponse
+	r.StartDatabase(PowerStatePayload{UUID: payload.UUID}, &startReply)
+	if startReply.Status != "ONLINE" {
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	err = r.makeWritable(payload.UUID.String())
+	if err != nil {
+		log.Println("Error making the previous deployment writable again:", err)
+		(*reply).Status = "ERROR"
+		return nil
+	}
+
+	(*reply).Status = "ROLLED_BACK"
+	return nil
+}
+
+// makeWritable undoes the read only mode the upgrade left on a deployment
+// that was just started again, unless its volume is still full.
+func (r *RPCServer) makeWritable(directoryUUID string) error {
+
+	if storageFull(directoryUUID) {
+		return nil
+	}
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	err = waitForDatabase(context.Background(), dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName)
+	if err != nil {
+		return err
+	}
+
+	return r.setReadOnly(directoryUUID, false)
+}
+
+func (r *RPCServer) discardDeployment(directoryUUID string) {
+	_, err := r.destroyDeployment(directoryUUID, false)
+	if err == nil {
+		return
+	}
+
+	log.Println("Error discarding deployment", directoryUUID, err)
+
+	err = purgeVolume(directoryUUID)
+	if err != nil {
+		log.Println("Error purging volume of discarded deployment", directoryUUID, err)
+	}
+	os.RemoveAll(directoryUUID)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+)
+
+const (
+	verificationTimeout   = 30 * time.Minute
+	verificationDatabase  = "verify"
+	verificationStorageMB = 1024
+)
+
+type VerifyBackupPayload struct {
+	SourceUUID uuid.UUID
+	BackupUUID uuid.UUID
+	Type       string
+	Version    string
+	User       string
+	Key        []byte
+	Checksum   string
+	StorageMB  int64
+}
+
+type VerifyBackupResponse struct {
+	Status string
+	Tables int
+	Rows   int64
+	Error  string
+}
+
+// VerifyBackup restores a backup into a throwaway deployment, reads every
+// table back and tears the deployment down again.
+func (r *RPCServer) VerifyBackup(payload VerifyBackupPayload, reply *VerifyBackupResponse) error {
+
+	directoryUUID := uuid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), verificationTimeout)
+	defer cancel()
+
+	err := os.MkdirAll(directoryUUID, 0750)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(directoryUUID, restoreMarkerFile), []byte(payload.BackupUUID.String()), 0600)
+	}
+	if err != nil {
+		log.Println("Error preparing backup verification:", err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+	defer r.discardDeployment(directoryUUID)
+
+	password := make([]byte, 16)
+	rand.Read(password)
+
+	storageMB := payload.StorageMB
+	if storageMB <= 0 {
+		storageMB = verificationStorageMB
+	}
+
+	dbPort, exporterPort, err := r.createDatabase(ctx, verificationDatabase, hex.EncodeToString(password), payload.User, payload.Type, payload.Version, directoryUUID, ComputeLimits{}, storageMB, bindAddress(ConnectivityPrivate))
+
+	dbPortNumber, _ := strconv.Atoi(dbPort)
+	exporterPortNumber, _ := strconv.Atoi(exporterPort)
+	r.releasePorts(dbPortNumber, exporterPortNumber)
+
+	if err == nil {
+		err = loadBackup(ctx, directoryUUID, payload.SourceUUID.String(), payload.BackupUUID.String(), payload.Key, payload.Checksum)
+	}
+
+	var tables int
+	var rows int64
+	if err == nil {
+		tables, rows, err = checkRestoredData(ctx, directoryUUID)
+	}
+
+	if err != nil {
+		log.Println("Backup", payload.BackupUUID, "failed verification:", err)
+		(*reply).Status = "ERROR"
+		(*reply).Error = err.Error()
+		return nil
+	}
+
+	(*reply).Status = "VERIFIED"
+	(*reply).Tables = tables
+	(*reply).Rows = rows
+	return nil
+}
+
+// checkRestoredData counts the user tables and scans each of them, so a
+// restore that loaded the schema but not readable data is caught.
+func checkRestoredData(ctx context.Context, directoryUUID string) (int, int64, error) {
+
+	vars, err := readTerraformVars(directoryUUID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return 0, 0, err
+	}
+	defer dockerClient.Close()
+
+	output, err := execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName,
+		"SELECT count(*), coalesce(string_agg(format('(SELECT count(*) FROM %I.%I)', schemaname, tablename), ' + '), '0') "+
+			"FROM pg_catalog.pg_tables WHERE schemaname NOT IN ('pg_catalog', 'information_schema')")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count, scan, _ := strings.Cut(strings.TrimSpace(output), "|")
+
+	tables, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	output, err = execSQL(ctx, dockerClient, vars.DbContainerName, vars.DbUser, vars.DbName, "SELECT "+scan)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	rows, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return tables, rows, nil
+}
@@ -2,7 +2,7 @@ terraform {
   required_providers {
     docker = {
       source = "kreuzwerker/docker"
-      version = "~> 3.0.1"
+      version = "~> 3.1"
     }
   }
 }
@@ -59,6 +59,45 @@ variable "node_ip" {
   type        = string
 }
 
+variable "data_path" {
+  description = "Host path of the database data directory"
+  type        = string
+}
+
+variable "db_bind_ip" {
+  description = "Host address the database port is published on"
+  type        = string
+  default     = "0.0.0.0"
+}
+
+variable "network_name" {
+  description = "Docker network shared by the database and its exporter"
+  type        = string
+  default     = "dbaas-deployments"
+}
+
+variable "cpu" {
+  description = "CPUs the database may use, 0 leaves it unlimited"
+  type        = number
+  default     = 0
+}
+
+variable "memory_mb" {
+  description = "Memory limit of the database in MB, 0 leaves it unlimited"
+  type        = number
+  default     = 0
+}
+
+variable "wal_archive_path" {
+  description = "Host path WAL segments are archived to, empty disables archiving"
+  type        = string
+  default     = ""
+}
+
+locals {
+  wal_archive_dir = "/var/lib/postgresql/wal-archive"
+}
+
 resource "docker_container" "example_db" {
   name  = var.db_container_name
   image = docker_image.postgres.image_id
@@ -66,12 +105,43 @@ resource "docker_container" "example_db" {
   env = [
     "POSTGRES_DB=${var.db_name}",
     "POSTGRES_USER=${var.db_user}",
-    "POSTGRES_PASSWORD=${var.db_password}"
+    "POSTGRES_PASSWORD=${var.db_password}",
+    "PGDATA=/var/lib/postgresql/data/pgdata"
   ]
 
+  cpus        = var.cpu > 0 ? tostring(var.cpu) : null
+  memory      = var.memory_mb > 0 ? var.memory_mb : null
+  memory_swap = var.memory_mb > 0 ? var.memory_mb : null
+
+  command = var.wal_archive_path == "" ? null : [
+    "postgres",
+    "-c", "wal_level=replica",
+    "-c", "archive_mode=on",
+    "-c", "archive_timeout=60",
+    "-c", "archive_command=test ! -f ${local.wal_archive_dir}/%f && cp %p ${local.wal_archive_dir}/%f.tmp && mv ${local.wal_archive_dir}/%f.tmp ${local.wal_archive_dir}/%f"
+  ]
+
+  networks_advanced {
+    name = var.network_name
+  }
+
   ports {
     internal = 5432
     external = var.db_port
+    ip       = var.db_bind_ip
+  }
+
+  volumes {
+    host_path      = var.data_path
+    container_path = "/var/lib/postgresql/data"
+  }
+
+  dynamic "volumes" {
+    for_each = var.wal_archive_path == "" ? [] : [var.wal_archive_path]
+    content {
+      host_path      = volumes.value
+      container_path = local.wal_archive_dir
+    }
   }
 }
 
@@ -80,9 +150,13 @@ resource "docker_container" "postgres_exporter" {
   image = docker_image.postgres_exporter.image_id
 
   env = [
-    "DATA_SOURCE_NAME=postgresql://${var.db_user}:${var.db_password}@${var.node_ip}:${var.db_port}/${var.db_name}?sslmode=disable",
+    "DATA_SOURCE_NAME=postgresql://${var.db_user}:${urlencode(var.db_password)}@${var.db_container_name}:5432/${var.db_name}?sslmode=disable",
   ]
 
+  networks_advanced {
+    name = var.network_name
+  }
+
   ports {
     internal = 9187
     external = var.exporter_port